@@ -0,0 +1,158 @@
+// Package config loads the server configuration from environment variables
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the server configuration
+type Config struct {
+	// Outbound message queue
+	SendQueueSize  int           // Messages buffered per client before the overflow policy applies
+	OverflowPolicy string        // "drop-oldest" or "disconnect"
+	WriteWait      time.Duration // Time allowed to write a single message to a client
+
+	// Heartbeat and read limits
+	PingInterval   time.Duration // How often the server pings each client
+	PongWait       time.Duration // How long to wait for any read (including pongs) before dropping a client
+	MaxMessageSize int64         // Largest inbound message accepted, in bytes
+
+	// Signed tokens
+	TokenSecret string        // HMAC key for session tokens; random per process when unset
+	SessionTTL  time.Duration // How long a player's session token can resume a seat
+	LoginTTL    time.Duration // How long a user stays logged in
+
+	// Reconnection
+	ReconnectGrace time.Duration // How long a disconnected player keeps their seat; zero removes them at once
+
+	// Rooms
+	RoomIdleTimeout time.Duration // How long a created room waits for its first client before it is closed
+
+	// Persistence
+	StoreBackend string // "memory" or "bolt"
+	StorePath    string // Database file used by the bolt backend
+}
+
+// current holds the active configuration
+var current = defaults()
+
+// defaults returns the default configuration
+func defaults() *Config {
+	return &Config{
+		SendQueueSize:  64,
+		OverflowPolicy: "drop-oldest",
+		WriteWait:      10 * time.Second,
+		PingInterval:   25 * time.Second,
+		PongWait:       60 * time.Second,
+		MaxMessageSize: 8192,
+		TokenSecret:    randomSecret(),
+		SessionTTL:     12 * time.Hour,
+		LoginTTL:       7 * 24 * time.Hour,
+		ReconnectGrace: 60 * time.Second,
+
+		RoomIdleTimeout: 10 * time.Minute,
+
+		StoreBackend: "memory",
+		StorePath:    "data/store.db",
+	}
+}
+
+// Load reads the configuration from environment variables, falling back to defaults
+func Load() *Config {
+	cfg := defaults()
+
+	cfg.SendQueueSize = envInt("SEND_QUEUE_SIZE", cfg.SendQueueSize)
+	cfg.OverflowPolicy = envString("SEND_OVERFLOW_POLICY", cfg.OverflowPolicy)
+	cfg.WriteWait = envDuration("WRITE_WAIT", cfg.WriteWait)
+	cfg.PingInterval = envDuration("PING_INTERVAL", cfg.PingInterval)
+	cfg.PongWait = envDuration("PONG_WAIT", cfg.PongWait)
+	cfg.MaxMessageSize = int64(envInt("MAX_MESSAGE_SIZE", int(cfg.MaxMessageSize)))
+
+	cfg.TokenSecret = envString("TOKEN_SECRET", cfg.TokenSecret)
+	cfg.SessionTTL = envDuration("SESSION_TTL", cfg.SessionTTL)
+	cfg.LoginTTL = envDuration("LOGIN_TTL", cfg.LoginTTL)
+	if os.Getenv("TOKEN_SECRET") == "" {
+		log.Printf("[CONFIG] TOKEN_SECRET not set, using a random secret; tokens will not survive a restart")
+	}
+
+	cfg.ReconnectGrace = envDuration("RECONNECT_GRACE", cfg.ReconnectGrace)
+	cfg.RoomIdleTimeout = envDuration("ROOM_IDLE_TIMEOUT", cfg.RoomIdleTimeout)
+
+	cfg.StoreBackend = envString("STORE_BACKEND", cfg.StoreBackend)
+	cfg.StorePath = envString("STORE_PATH", cfg.StorePath)
+
+	// Pings must be sent more often than the read deadline expires
+	if cfg.PingInterval >= cfg.PongWait {
+		log.Printf("[CONFIG] PING_INTERVAL %s must be shorter than PONG_WAIT %s, adjusting", cfg.PingInterval, cfg.PongWait)
+		cfg.PingInterval = cfg.PongWait * 9 / 10
+	}
+
+	current = cfg
+	return cfg
+}
+
+// String formats the configuration for logging with the token secret redacted
+func (c Config) String() string {
+	type plain Config
+	redacted := plain(c)
+	if redacted.TokenSecret != "" {
+		redacted.TokenSecret = "[redacted]"
+	}
+	return fmt.Sprintf("%+v", redacted)
+}
+
+// Get returns the active configuration
+func Get() *Config {
+	return current
+}
+
+// randomSecret generates a random token secret
+func randomSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("[CONFIG] Failed to generate token secret: %v", err)
+	}
+	return hex.EncodeToString(secret)
+}
+
+// envString reads a string environment variable
+func envString(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// envInt reads an integer environment variable
+func envInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("[CONFIG] Invalid %s=%q, using default %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return intValue
+}
+
+// envDuration reads a duration environment variable such as "30s"
+func envDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("[CONFIG] Invalid %s=%q, using default %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// OverflowPolicy decides what happens when a client's outbound queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowDropOldest evicts the oldest queued time update to make room
+	OverflowDropOldest OverflowPolicy = iota
+	// OverflowDisconnect closes the connection of a client that cannot keep up
+	OverflowDisconnect
+)
+
+// ParseOverflowPolicy converts a configuration value into an overflow policy
+func ParseOverflowPolicy(value string) OverflowPolicy {
+	if value == "disconnect" {
+		return OverflowDisconnect
+	}
+	return OverflowDropOldest
+}
+
+// DisconnectReason explains why a client's connection ended
+type DisconnectReason string
+
+const (
+	// DisconnectClosed means the client closed the connection
+	DisconnectClosed DisconnectReason = "closed"
+	// DisconnectTimeout means the client stopped answering pings
+	DisconnectTimeout DisconnectReason = "timeout"
+	// DisconnectMessageTooLarge means the client sent a message over the size limit
+	DisconnectMessageTooLarge DisconnectReason = "message_too_large"
+	// DisconnectSlowClient means the client's outbound queue overflowed
+	DisconnectSlowClient DisconnectReason = "slow_client"
+	// DisconnectWriteError means a write to the client failed
+	DisconnectWriteError DisconnectReason = "write_error"
+	// DisconnectReadError means reading from the client failed unexpectedly
+	DisconnectReadError DisconnectReason = "read_error"
+	// DisconnectReplaced means the player resumed the seat on a newer connection
+	DisconnectReplaced DisconnectReason = "replaced"
+	// DisconnectGraceExpired means an away player did not reconnect within the grace period
+	DisconnectGraceExpired DisconnectReason = "grace_expired"
+)
+
+// SendOptions configures a client's outbound queue and write pump
+type SendOptions struct {
+	QueueSize    int
+	Policy       OverflowPolicy
+	WriteWait    time.Duration
+	PingInterval time.Duration // Zero disables keepalive pings
+}
+
+// outboundMessage is a serialized message waiting in a client's queue
+type outboundMessage struct {
+	data      []byte
+	droppable bool
+}
+
+// Player returns the client's public player information
+func (c *Client) Player() Player {
+	return Player{
+		Nickname:    c.Nickname,
+		ID:          c.ID,
+		UserID:      c.UserID,
+		IsHost:      c.IsHost,
+		Score:       c.Score,
+		Avatar:      c.Avatar,
+		Away:        c.Away,
+		Team:        c.Team,
+		IsSpectator: c.IsSpectator,
+	}
+}
+
+// TakeSeat moves the seat state of a previous connection onto this client
+func (c *Client) TakeSeat(previous *Client) {
+	c.UserID = previous.UserID
+	c.Nickname = previous.Nickname
+	c.IsHost = previous.IsHost
+	c.Score = previous.Score
+	c.Avatar = previous.Avatar
+	c.GameFinished = previous.GameFinished
+	c.Team = previous.Team
+	c.IsSpectator = previous.IsSpectator
+	c.Away = false
+}
+
+// IsDroppable reports whether a message may be discarded when its client falls behind.
+// Time updates are superseded by the next tick, so losing one is harmless.
+func IsDroppable(message map[string]interface{}) bool {
+	msgType, _ := message["type"].(string)
+	return strings.HasSuffix(msgType, "-timeupdate")
+}
+
+// NewClient creates a client for a connection with an empty outbound queue.
+// Messages may be queued right away; they are written once StartWritePump runs.
+func NewClient(conn *websocket.Conn, options SendOptions) *Client {
+	if options.QueueSize <= 0 {
+		options.QueueSize = 64
+	}
+	return &Client{
+		Conn:    conn,
+		send:    make(chan outboundMessage, options.QueueSize),
+		options: options,
+		done:    make(chan struct{}),
+	}
+}
+
+// StartWritePump starts the client's writer goroutine
+func (c *Client) StartWritePump() {
+	go c.writePump()
+}
+
+// writePump writes queued messages and keepalive pings to the connection until the client is closed
+func (c *Client) writePump() {
+	var pings <-chan time.Time
+	if c.options.PingInterval > 0 {
+		ticker := time.NewTicker(c.options.PingInterval)
+		defer ticker.Stop()
+		pings = ticker.C
+	}
+
+	for {
+		select {
+		case msg := <-c.send:
+			if err := c.write(websocket.TextMessage, msg.data); err != nil {
+				log.Printf("[CLIENT] Write error to %s: %v", c.Nickname, err)
+				c.Close(DisconnectWriteError)
+				return
+			}
+		case <-pings:
+			if err := c.write(websocket.PingMessage, nil); err != nil {
+				log.Printf("[CLIENT] Ping error to %s: %v", c.Nickname, err)
+				c.Close(DisconnectWriteError)
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// write writes a single frame to the client's connection
+func (c *Client) write(messageType int, data []byte) error {
+	if c.options.WriteWait > 0 {
+		c.Conn.SetWriteDeadline(time.Now().Add(c.options.WriteWait))
+	}
+	return c.Conn.WriteMessage(messageType, data)
+}
+
+// Send serializes a message and queues it for the write pump
+func (c *Client) Send(message map[string]interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling message for %s: %v", c.Nickname, err)
+		return
+	}
+	c.SendRaw(data, IsDroppable(message))
+}
+
+// SendRaw queues an already serialized message without blocking the caller.
+// When the queue is full the client's overflow policy decides what is dropped.
+func (c *Client) SendRaw(data []byte, droppable bool) {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	msg := outboundMessage{data: data, droppable: droppable}
+	select {
+	case c.send <- msg:
+		return
+	default:
+	}
+
+	if c.options.Policy == OverflowDropOldest && c.evictOldestDroppable() {
+		c.send <- msg
+		return
+	}
+
+	log.Printf("[CLIENT] Outbound queue full for %s, disconnecting", c.Nickname)
+	c.Close(DisconnectSlowClient)
+}
+
+// evictOldestDroppable removes the oldest droppable message from the queue,
+// keeping the order of the remaining messages. Must hold sendMutex.
+func (c *Client) evictOldestDroppable() bool {
+	pending := make([]outboundMessage, 0, len(c.send))
+	for drained := false; !drained; {
+		select {
+		case msg := <-c.send:
+			pending = append(pending, msg)
+		default:
+			drained = true
+		}
+	}
+
+	evicted := false
+	for _, msg := range pending {
+		if !evicted && msg.droppable {
+			evicted = true
+			continue
+		}
+		c.send <- msg
+	}
+	return evicted || len(pending) < cap(c.send)
+}
+
+// Close stops the write pump and closes the client's connection.
+// The first reason given is kept and reported by CloseReason.
+func (c *Client) Close(reason DisconnectReason) {
+	c.closeOnce.Do(func() {
+		c.Mutex.Lock()
+		c.closeReason = reason
+		c.Mutex.Unlock()
+
+		close(c.done)
+		if c.Conn != nil {
+			c.Conn.Close()
+		}
+	})
+}
+
+// CloseReason returns the reason passed to Close, or "" if the server has not closed the client
+func (c *Client) CloseReason() DisconnectReason {
+	c.Mutex.RLock()
+	defer c.Mutex.RUnlock()
+	return c.closeReason
+}
@@ -0,0 +1,75 @@
+package core
+
+import "testing"
+
+// queued drains a client's outbound queue
+func queued(c *Client) []string {
+	var messages []string
+	for {
+		select {
+		case msg := <-c.send:
+			messages = append(messages, string(msg.data))
+		default:
+			return messages
+		}
+	}
+}
+
+func TestFullQueueEvictsOldestDroppable(t *testing.T) {
+	client := NewClient(nil, SendOptions{QueueSize: 3, Policy: OverflowDropOldest})
+	client.SendRaw([]byte("tick1"), true)
+	client.SendRaw([]byte("join"), false)
+	client.SendRaw([]byte("tick2"), true)
+	client.SendRaw([]byte("score"), false)
+
+	got := queued(client)
+	want := []string{"join", "tick2", "score"}
+	if len(got) != len(want) {
+		t.Fatalf("queue = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("queue = %v, want %v", got, want)
+		}
+	}
+	if reason := client.CloseReason(); reason != "" {
+		t.Fatalf("client closed with %q", reason)
+	}
+}
+
+func TestFullQueueWithoutDroppableDisconnects(t *testing.T) {
+	client := NewClient(nil, SendOptions{QueueSize: 2, Policy: OverflowDropOldest})
+	client.SendRaw([]byte("a"), false)
+	client.SendRaw([]byte("b"), false)
+	client.SendRaw([]byte("c"), false)
+
+	if reason := client.CloseReason(); reason != DisconnectSlowClient {
+		t.Fatalf("CloseReason() = %q, want %q", reason, DisconnectSlowClient)
+	}
+}
+
+func TestDisconnectPolicyNeverEvicts(t *testing.T) {
+	client := NewClient(nil, SendOptions{QueueSize: 1, Policy: OverflowDisconnect})
+	client.SendRaw([]byte("tick1"), true)
+	client.SendRaw([]byte("tick2"), true)
+
+	if reason := client.CloseReason(); reason != DisconnectSlowClient {
+		t.Fatalf("CloseReason() = %q, want %q", reason, DisconnectSlowClient)
+	}
+	if got := queued(client); len(got) != 1 || got[0] != "tick1" {
+		t.Fatalf("queue = %v, want [tick1]", got)
+	}
+}
+
+func TestClosedClientDropsMessages(t *testing.T) {
+	client := NewClient(nil, SendOptions{QueueSize: 2})
+	client.Close(DisconnectClosed)
+	client.SendRaw([]byte("late"), false)
+
+	if got := queued(client); len(got) != 0 {
+		t.Fatalf("queue = %v after close, want empty", got)
+	}
+	if reason := client.CloseReason(); reason != DisconnectClosed {
+		t.Fatalf("CloseReason() = %q, want %q", reason, DisconnectClosed)
+	}
+}
@@ -0,0 +1,48 @@
+package core
+
+import "fmt"
+
+// Error codes sent back to clients in error messages
+const (
+	ErrCodeInvalidMessage     = "INVALID_MESSAGE"
+	ErrCodeInvalidPayload     = "INVALID_PAYLOAD"
+	ErrCodeUnsupportedVersion = "UNSUPPORTED_VERSION"
+	ErrCodeUnknownMessageType = "UNKNOWN_MESSAGE_TYPE"
+	ErrCodeUnknownGameType    = "UNKNOWN_GAME_TYPE"
+	ErrCodeWrongGameType      = "WRONG_GAME_TYPE"
+	ErrCodeNotHost            = "NOT_HOST"
+	ErrCodeNotPlayer          = "NOT_PLAYER"
+	ErrCodeGameInProgress     = "GAME_IN_PROGRESS"
+	ErrCodeNoActiveGame       = "NO_ACTIVE_GAME"
+	ErrCodeGameEnded          = "GAME_ENDED"
+	ErrCodeGamePaused         = "GAME_PAUSED"
+	ErrCodeScoreRejected      = "SCORE_REJECTED"
+	ErrCodeNotEnoughPlayers   = "NOT_ENOUGH_PLAYERS"
+	ErrCodeRoomFull           = "ROOM_FULL"
+	ErrCodeRoomClosed         = "ROOM_CLOSED"
+	ErrCodeInternal           = "INTERNAL_ERROR"
+)
+
+// FieldError describes a single payload field that failed validation
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+// Error is a failure reported back to the client that sent the triggering message
+type Error struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// NewError creates an error with a machine-readable code
+func NewError(code string, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
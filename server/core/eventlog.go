@@ -0,0 +1,110 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// maxLoggedEvents caps a single game's event log; later events are dropped
+const maxLoggedEvents = 100000
+
+// Event kinds recorded in a game's event log
+const (
+	EventStart     = "start"     // The game started with the settings in Message
+	EventInbound   = "inbound"   // A client sent Message
+	EventBroadcast = "broadcast" // Message was sent to the audience in To
+	EventTick      = "tick"      // The game timer ticked
+	EventScore     = "score"     // A player's score changed
+	EventEnd       = "end"       // The game ended and was recorded
+)
+
+// Broadcast audiences
+const (
+	AudienceRoom              = "room"
+	AudienceHost              = "host"
+	AudiencePlayers           = "players"
+	AudienceSpectators        = "spectators"
+	AudienceHostAndSpectators = "hostAndSpectators"
+)
+
+// Event is one entry of a game's event log
+type Event struct {
+	Seq      int             `json:"seq"`
+	At       int64           `json:"at"` // Milliseconds since the game started, on the monotonic clock
+	Kind     string          `json:"kind"`
+	PlayerID string          `json:"playerId,omitempty"` // Sender of an inbound message or player whose score changed
+	To       string          `json:"to,omitempty"`       // Audience of a broadcast
+	Message  json.RawMessage `json:"message,omitempty"`  // The message as it went over the wire
+	Data     interface{}     `json:"data,omitempty"`     // Tick, score and end details
+}
+
+// EventLog records what happened during one game. It is owned by the room's event loop.
+type EventLog struct {
+	started time.Time
+	events  []Event
+	full    bool
+}
+
+// NewEventLog starts an empty event log timed from now
+func NewEventLog() *EventLog {
+	return &EventLog{started: time.Now()}
+}
+
+// Record appends an event, numbering and timestamping it
+func (l *EventLog) Record(event Event) {
+	if len(l.events) >= maxLoggedEvents {
+		if !l.full {
+			log.Printf("[EVENTLOG] Event log is full after %d events, dropping the rest", maxLoggedEvents)
+			l.full = true
+		}
+		return
+	}
+	event.Seq = len(l.events) + 1
+	event.At = time.Since(l.started).Milliseconds()
+	l.events = append(l.events, event)
+}
+
+// Events returns the recorded events in order
+func (l *EventLog) Events() []Event {
+	return l.events
+}
+
+// JSONLines encodes the events one JSON object per line
+func (l *EventLog) JSONLines() ([]byte, error) {
+	var lines []byte
+	for _, event := range l.events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line...)
+		lines = append(lines, '\n')
+	}
+	return lines, nil
+}
+
+// LogEvent records an event in the running game's event log, if there is one.
+// It must be called on the room's event loop.
+func (r *Room) LogEvent(event Event) {
+	if r.EventLog != nil {
+		r.EventLog.Record(event)
+	}
+}
+
+// LogScore records a player's current score in the running game's event log
+func (r *Room) LogScore(client *Client) {
+	r.LogEvent(Event{
+		Kind:     EventScore,
+		PlayerID: client.ID,
+		Data:     map[string]int{"score": client.Score},
+	})
+}
+
+// LogTick records a game timer tick with the time left in the running game's event log
+func (r *Room) LogTick(timeLeft int) {
+	r.LogEvent(Event{
+		Kind: EventTick,
+		Data: map[string]int{"timeLeft": timeLeft},
+	})
+}
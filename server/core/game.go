@@ -0,0 +1,49 @@
+package core
+
+import "time"
+
+// Game represents a live game instance owned by a room
+type Game interface {
+	// Type returns the registered game type, e.g. "memory"
+	Type() string
+	// Start begins the game using the settings carried by the start message
+	Start(message Message) error
+	// HandleMessage processes an in-game message sent by a client
+	HandleMessage(client *Client, message Message) error
+	// OnPlayerJoin is called when a client joins the room while the game exists
+	OnPlayerJoin(client *Client)
+	// OnPlayerLeave is called when a client leaves the room while the game exists
+	OnPlayerLeave(client *Client)
+	// Snapshot returns the current game state (settings, remaining time, scores);
+	// it is sent to clients that resume their seat mid-game
+	Snapshot() interface{}
+	// End stops the game timers and broadcasts the final results
+	End()
+}
+
+// PlayerSnapshotter is implemented by games whose resync holds state only
+// the resuming player may see. The platform sends PlayerSnapshot instead of
+// Snapshot to that player.
+type PlayerSnapshotter interface {
+	PlayerSnapshot(client *Client) interface{}
+}
+
+// Resumer is implemented by games with deadlines of their own, such as items
+// on screen that expire. The platform calls OnResume with the length of a
+// pause so the game can push them back.
+type Resumer interface {
+	OnResume(paused time.Duration)
+}
+
+// GameFactory creates a new game instance bound to a room
+type GameFactory func(room *Room) Game
+
+// GameDefinition describes a game type that can be created by the platform
+type GameDefinition struct {
+	Type         string                 // Game type, e.g. "memory"
+	StartMessage string                 // Message type that starts the game, e.g. "memory-startgame"
+	StartPayload interface{}            // Settings payload prototype decoded for Start
+	Messages     map[string]interface{} // In-game message types and their payload prototypes
+	HostMessages []string               // In-game message types only the host may send; the start message always is
+	Factory      GameFactory            // Creates a new game instance for a room
+}
@@ -0,0 +1,161 @@
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"gaming-platform/core"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ProtocolVersion is the highest message envelope version understood by the server
+const ProtocolVersion = 1
+
+// payloadValidator validates payload structs using their `validate` tags
+var payloadValidator = newPayloadValidator()
+
+// newPayloadValidator creates a validator that reports fields by their JSON names
+func newPayloadValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// rawEnvelope is the wire format accepted from clients
+type rawEnvelope struct {
+	Type    string          `json:"type"`
+	Version int             `json:"version"`
+	ID      string          `json:"id"`
+	Payload json.RawMessage `json:"payload"`
+	// Data is the payload key used by older clients
+	Data json.RawMessage `json:"data"`
+}
+
+// ParseEnvelope parses raw message bytes into a message envelope.
+// Clients that predate the envelope send their payload under "data" or as
+// top-level fields; both are accepted as the payload.
+func ParseEnvelope(msgData []byte) (core.Message, error) {
+	var raw rawEnvelope
+	if err := json.Unmarshal(msgData, &raw); err != nil {
+		return core.Message{}, &core.Error{
+			Code:    core.ErrCodeInvalidMessage,
+			Message: fmt.Sprintf("malformed message: %v", err),
+		}
+	}
+
+	msg := core.Message{
+		Type:    raw.Type,
+		Version: raw.Version,
+		ID:      raw.ID,
+		Payload: raw.Payload,
+	}
+	if msg.Version == 0 {
+		msg.Version = ProtocolVersion
+	}
+
+	if msg.Type == "" {
+		return msg, &core.Error{Code: core.ErrCodeInvalidMessage, Message: "message type is required"}
+	}
+	if msg.Version > ProtocolVersion {
+		return msg, &core.Error{
+			Code:    core.ErrCodeUnsupportedVersion,
+			Message: fmt.Sprintf("message version %d is not supported (max %d)", msg.Version, ProtocolVersion),
+		}
+	}
+
+	// Fall back to the legacy payload locations
+	if isEmptyPayload(msg.Payload) {
+		if !isEmptyPayload(raw.Data) {
+			msg.Payload = raw.Data
+		} else {
+			msg.Payload = msgData
+		}
+	}
+
+	return msg, nil
+}
+
+// DecodePayload decodes the message payload into a new value of the
+// prototype's type, validates it and stores the pointer in message.Data.
+// A nil prototype means the message carries no payload.
+func DecodePayload(message *core.Message, prototype interface{}) error {
+	if prototype == nil {
+		message.Data = nil
+		return nil
+	}
+
+	payloadType := reflect.TypeOf(prototype)
+	if payloadType.Kind() == reflect.Ptr {
+		payloadType = payloadType.Elem()
+	}
+	payload := reflect.New(payloadType).Interface()
+
+	if !isEmptyPayload(message.Payload) {
+		if err := json.Unmarshal(message.Payload, payload); err != nil {
+			return &core.Error{
+				Code:    core.ErrCodeInvalidPayload,
+				Message: fmt.Sprintf("invalid %s payload: %v", message.Type, err),
+			}
+		}
+	}
+
+	if err := payloadValidator.Struct(payload); err != nil {
+		payloadErr := &core.Error{
+			Code:    core.ErrCodeInvalidPayload,
+			Message: fmt.Sprintf("invalid %s payload", message.Type),
+		}
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			for _, fieldErr := range validationErrs {
+				payloadErr.Fields = append(payloadErr.Fields, core.FieldError{
+					Field: strings.SplitN(fieldErr.Namespace(), ".", 2)[1],
+					Rule:  fieldErr.Tag(),
+					Param: fieldErr.Param(),
+				})
+			}
+		}
+		return payloadErr
+	}
+
+	message.Data = payload
+	return nil
+}
+
+// SendError reports a failed message back to the client that sent it.
+// Errors that do not carry a code are reported as internal errors.
+func SendError(client *core.Client, message core.Message, err error) {
+	var clientErr *core.Error
+	if !errors.As(err, &clientErr) {
+		clientErr = &core.Error{Code: core.ErrCodeInternal, Message: err.Error()}
+	}
+
+	log.Printf("[WEBSOCKET] Rejected %s from %s: %v", message.Type, client.Nickname, clientErr)
+	SendMessage(client, map[string]interface{}{
+		"type": "error",
+		"data": map[string]interface{}{
+			"code":          clientErr.Code,
+			"message":       clientErr.Message,
+			"fields":        clientErr.Fields,
+			"correlationId": message.ID,
+			"requestType":   message.Type,
+		},
+	})
+}
+
+// isEmptyPayload reports whether a raw payload is absent or null
+func isEmptyPayload(payload json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(payload)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
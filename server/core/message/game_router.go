@@ -1,44 +1,230 @@
 package message
 
 import (
+	"encoding/json"
 	"log"
+	"sync"
 
 	"gaming-platform/core"
-	"gaming-platform/core/interfaces"
 )
 
-// gameStartHandlers stores game-specific start handlers
-var gameStartHandlers = make(map[string]interfaces.GameStartHandler)
+// GameRegistry stores the game definitions registered by game modules
+type GameRegistry struct {
+	games        map[string]core.GameDefinition // keyed by game type
+	startTypes   map[string]string              // start message type -> game type
+	messageTypes map[string]string              // in-game message type -> game type
+	hostMessages map[string]bool                // message types only the host may send
+	mutex        sync.RWMutex
+}
+
+// Global game registry instance
+var gameRegistry = &GameRegistry{
+	games:        make(map[string]core.GameDefinition),
+	startTypes:   make(map[string]string),
+	messageTypes: make(map[string]string),
+	hostMessages: make(map[string]bool),
+}
+
+// RegisterGame registers a game definition so rooms can create instances of it
+func RegisterGame(def core.GameDefinition) {
+	gameRegistry.mutex.Lock()
+	defer gameRegistry.mutex.Unlock()
+
+	if _, exists := gameRegistry.games[def.Type]; exists {
+		log.Printf("[GAME_ROUTER] Warning: Overwriting existing game definition: %s", def.Type)
+	}
+
+	gameRegistry.games[def.Type] = def
+	if def.StartMessage != "" {
+		gameRegistry.startTypes[def.StartMessage] = def.Type
+		gameRegistry.hostMessages[def.StartMessage] = true
+	}
+	for msgType := range def.Messages {
+		gameRegistry.messageTypes[msgType] = def.Type
+	}
+	for _, msgType := range def.HostMessages {
+		gameRegistry.hostMessages[msgType] = true
+	}
+	log.Printf("[GAME_ROUTER] Registered game type: %s", def.Type)
+}
+
+// GetGame retrieves a game definition by game type
+func GetGame(gameType string) (core.GameDefinition, bool) {
+	gameRegistry.mutex.RLock()
+	defer gameRegistry.mutex.RUnlock()
+
+	def, exists := gameRegistry.games[gameType]
+	return def, exists
+}
+
+// ListGameTypes returns all registered game types
+func ListGameTypes() []string {
+	gameRegistry.mutex.RLock()
+	defer gameRegistry.mutex.RUnlock()
+
+	types := make([]string, 0, len(gameRegistry.games))
+	for gameType := range gameRegistry.games {
+		types = append(types, gameType)
+	}
+	return types
+}
+
+// isHostGameMessage reports whether a game message type may only be sent by the host
+func isHostGameMessage(msgType string) bool {
+	gameRegistry.mutex.RLock()
+	defer gameRegistry.mutex.RUnlock()
+
+	return gameRegistry.hostMessages[msgType]
+}
+
+// gameTypeForStartMessage returns the game type started by a message type
+func gameTypeForStartMessage(msgType string) (string, bool) {
+	gameRegistry.mutex.RLock()
+	defer gameRegistry.mutex.RUnlock()
+
+	gameType, exists := gameRegistry.startTypes[msgType]
+	return gameType, exists
+}
+
+// gameTypeForMessage returns the game type that handles an in-game message type
+func gameTypeForMessage(msgType string) (string, bool) {
+	gameRegistry.mutex.RLock()
+	defer gameRegistry.mutex.RUnlock()
+
+	gameType, exists := gameRegistry.messageTypes[msgType]
+	return gameType, exists
+}
+
+// StartGame creates a new game instance for the room and starts it
+// The router only lets the host send start messages.
+func StartGame(room *core.Room, client *core.Client, gameType string, message core.Message) error {
+	// Rooms only run the game they were created for
+	if gameType == "" {
+		gameType = room.GameType
+	}
+	if room.GameType != "" && gameType != room.GameType {
+		return core.NewError(core.ErrCodeWrongGameType, "room %s was created for %s, not %s", room.ID, room.GameType, gameType)
+	}
+	if room.Tournament.Running() {
+		return core.NewError(core.ErrCodeGameInProgress, "a tournament is running in room %s", room.ID)
+	}
+
+	// Settings sent by the host override the ones the room was created with
+	payload, err := mergeSettings(room.Settings, message.Payload)
+	if err != nil {
+		return err
+	}
+	message.Payload = payload
 
-// RegisterGameStartHandler registers a game-specific start handler
-func RegisterGameStartHandler(gameType string, handler interfaces.GameStartHandler) {
-	gameStartHandlers[gameType] = handler
-	log.Printf("[GAME_ROUTER] Registered start handler for game type: %s", gameType)
+	return startGame(room, gameType, message)
 }
 
-// HandleHostStartGameRouter routes hostStartGame messages to appropriate game handlers
-func HandleHostStartGameRouter(room *core.Room, client *core.Client, message core.Message) {
-	// Extract message data
-	dataMap, ok := message.Data.(map[string]interface{})
-	if !ok {
-		log.Printf("[GAME_ROUTER] Invalid message data format")
+// StartRound starts a tournament round, which may be of any registered game
+// type, with the round's own settings
+func StartRound(room *core.Room, gameType string, settings json.RawMessage) error {
+	def, exists := GetGame(gameType)
+	if !exists {
+		return core.NewError(core.ErrCodeUnknownGameType, "unknown game type: %s", gameType)
+	}
+	return startGame(room, gameType, core.Message{Type: def.StartMessage, Version: 1, Payload: settings})
+}
+
+// startGame creates the game instance and starts it with the settings in the start message
+func startGame(room *core.Room, gameType string, message core.Message) error {
+	def, exists := GetGame(gameType)
+	if !exists {
+		return core.NewError(core.ErrCodeUnknownGameType, "unknown game type: %s", gameType)
+	}
+
+	if room.Game != nil && room.GameStarted && !room.GameEnded {
+		return core.NewError(core.ErrCodeGameInProgress, "%s is already running in room %s", room.Game.Type(), room.ID)
+	}
+
+	// Decode the game's own settings payload from the start message
+	if err := DecodePayload(&message, def.StartPayload); err != nil {
+		return err
+	}
+
+	// Every game starts running, whatever state the previous one was left in
+	room.Paused = false
+	room.PausedFor = 0
+
+	// Every game gets its own event log, starting with its settings
+	room.EventLog = core.NewEventLog()
+	room.LogEvent(core.Event{Kind: core.EventStart, Message: message.Payload, Data: map[string]string{"gameType": gameType}})
+
+	game := def.Factory(room)
+	room.Game = game
+	if err := game.Start(message); err != nil {
+		log.Printf("[GAME_ROUTER] Failed to start %s in room %s: %v", gameType, room.ID, err)
+		room.Game = nil
+		room.EventLog = nil
+		return err
+	}
+	log.Printf("[GAME_ROUTER] Started %s in room %s", gameType, room.ID)
+	return nil
+}
+
+// ValidateSettings checks that settings are valid start settings for a game type
+func ValidateSettings(gameType string, settings json.RawMessage) error {
+	def, exists := GetGame(gameType)
+	if !exists {
+		return core.NewError(core.ErrCodeUnknownGameType, "unknown game type: %s", gameType)
+	}
+	message := core.Message{Type: def.StartMessage, Payload: settings}
+	return DecodePayload(&message, def.StartPayload)
+}
+
+// mergeSettings overlays the top-level fields of a start payload on a room's default settings
+func mergeSettings(defaults, payload json.RawMessage) (json.RawMessage, error) {
+	if isEmptyPayload(defaults) {
+		return payload, nil
+	}
+	if isEmptyPayload(payload) {
+		return defaults, nil
+	}
+
+	merged := map[string]json.RawMessage{}
+	if err := json.Unmarshal(defaults, &merged); err != nil {
+		return nil, err
+	}
+	var overrides map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &overrides); err != nil {
+		return nil, core.NewError(core.ErrCodeInvalidPayload, "invalid start payload: %v", err)
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return json.Marshal(merged)
+}
+
+// EndGame ends the room's active game, if any
+func EndGame(room *core.Room) {
+	if room.Game == nil {
 		return
 	}
+	room.Game.End()
+}
+
+// routeGameMessage delivers an in-game message to the room's active game
+func routeGameMessage(room *core.Room, client *core.Client, gameType string, message core.Message) error {
+	if room.Game == nil || room.Game.Type() != gameType {
+		return core.NewError(core.ErrCodeNoActiveGame, "no active %s game in room %s", gameType, room.ID)
+	}
+	if room.Paused {
+		return core.NewError(core.ErrCodeGamePaused, "the game in room %s is paused", room.ID)
+	}
 
-	// Check game type in the data
-	data, dataOk := dataMap["data"].(map[string]interface{})
-	if dataOk {
-		if gameType, ok := data["gameType"].(string); ok {
-			// Look for registered game start handler
-			if handler, exists := gameStartHandlers[gameType]; exists {
-				log.Printf("[GAME_ROUTER] Routing %s hostStartGame to registered handler", gameType)
-				handler(room, client, message)
-				return
-			}
-			log.Printf("[GAME_ROUTER] No registered handler for game type: %s", gameType)
-			return
-		}
+	def, _ := GetGame(gameType)
+	if err := DecodePayload(&message, def.Messages[message.Type]); err != nil {
+		return err
 	}
 
-	log.Printf("[GAME_ROUTER] No game type specified in hostStartGame message")
-}
\ No newline at end of file
+	return room.Game.HandleMessage(client, message)
+}
+
+// HandleHostStartGameRouter routes hostStartGame messages to the game named in the payload
+func HandleHostStartGameRouter(room *core.Room, client *core.Client, message core.Message) error {
+	payload := message.Data.(*StartGamePayload)
+	return StartGame(room, client, payload.GameType, message)
+}
@@ -4,56 +4,73 @@ package message
 import (
 	"encoding/json"
 	"log"
+	"time"
 
 	"gaming-platform/core"
 )
 
-// HandleMessage handles incoming WebSocket messages from clients
+// init registers the core platform message handlers
+func init() {
+	RegisterHandler("join", handleJoinMessage, nil)
+	RegisterHostHandler("hostStartGame", HandleHostStartGameRouter, StartGamePayload{})
+	RegisterHostHandler("startGameWithNotification", handleStartGameWithNotification, StartGamePayload{})
+	RegisterHostHandler("notifyPlatformPlayers", handleNotifyPlatformPlayers, NotifyPlayersPayload{})
+	RegisterHostHandler("hostCloseGame", handleHostCloseGame, nil)
+	RegisterHostHandler("hostPauseGame", handleHostPauseGame, nil)
+	RegisterHostHandler("hostResumeGame", handleHostResumeGame, nil)
+	RegisterHostHandler("hostStopGame", handleHostStopGame, nil)
+}
+
+// HandleMessage handles incoming WebSocket messages from clients.
+// Any failure is reported back to the client as an error message that
+// carries the triggering message's ID as its correlation ID.
 func HandleMessage(client *core.Client, room *core.Room, msgData []byte) {
-	var msg map[string]interface{}
-	err := json.Unmarshal(msgData, &msg)
-	if err != nil {
-		log.Printf("Error unmarshaling message: %v", err)
-		return
+	msg, err := ParseEnvelope(msgData)
+	if err == nil {
+		room.LogEvent(core.Event{Kind: core.EventInbound, PlayerID: client.ID, Message: msgData})
+		err = dispatchMessage(client, room, msg)
 	}
-
-	msgType, ok := msg["type"].(string)
-	if !ok {
-		log.Printf("Invalid message type from %s", client.Nickname)
-		return
+	if err != nil {
+		SendError(client, msg, err)
 	}
+}
 
-	log.Printf("[DEBUG] handleMessage called with msg type: %s", msgType)
+// dispatchMessage routes a parsed message to its platform handler or game
+func dispatchMessage(client *core.Client, room *core.Room, msg core.Message) error {
+	log.Printf("[DEBUG] handleMessage called with msg type: %s", msg.Type)
 
 	// Check if there's a registered handler for this message type
-	if handler, exists := GetHandler(msgType); exists {
-		// Convert to core.Message format
-		coreMsg := core.Message{
-			Type: msgType,
-			Data: msg,
+	if registration, exists := GetHandler(msg.Type); exists {
+		if registration.HostOnly && !client.IsHost {
+			return notHostError(msg)
 		}
-		// Call the registered handler
-		handler(room, client, coreMsg)
-		return
+		if err := DecodePayload(&msg, registration.Payload); err != nil {
+			return err
+		}
+		return registration.Handler(room, client, msg)
 	}
 
-	// Handle core platform messages that don't need registration
-	switch msgType {
-	case "join":
-		handleJoinMessage(client, room)
-	case "startGameWithNotification":
-		handleStartGameWithNotification(client, room, msg)
-	case "notifyPlatformPlayers":
-		handleNotifyPlatformPlayers(client, room, msg)
-	case "hostCloseGame":
-		handleHostCloseGame(client, room)
-	default:
-		log.Printf("[WEBSOCKET] Unhandled message type: %s from %s", msgType, client.Nickname)
+	// Route game start and in-game messages to the registered games
+	if isHostGameMessage(msg.Type) && !client.IsHost {
+		return notHostError(msg)
+	}
+	if gameType, exists := gameTypeForStartMessage(msg.Type); exists {
+		return StartGame(room, client, gameType, msg)
+	}
+	if gameType, exists := gameTypeForMessage(msg.Type); exists {
+		return routeGameMessage(room, client, gameType, msg)
 	}
+
+	return core.NewError(core.ErrCodeUnknownMessageType, "unknown message type: %s", msg.Type)
+}
+
+// notHostError reports a host-only message sent by someone else
+func notHostError(msg core.Message) error {
+	return core.NewError(core.ErrCodeNotHost, "only the host can send %s", msg.Type)
 }
 
 // handleJoinMessage handles join messages
-func handleJoinMessage(client *core.Client, room *core.Room) {
+func handleJoinMessage(room *core.Room, client *core.Client, message core.Message) error {
 	log.Printf("[WEBSOCKET] %s joined room %s", client.Nickname, room.ID)
 	
 	// Game state will be sent by the specific game handlers
@@ -61,23 +78,26 @@ func handleJoinMessage(client *core.Client, room *core.Room) {
 	
 	// Broadcast player list update to all clients in the room
 	broadcastPlayerListUpdate(room)
+	return nil
 }
 
-// SendMessage sends a message to a specific client
+// SendMessage queues a message for a specific client
 func SendMessage(client *core.Client, message map[string]interface{}) {
-	client.Mutex.Lock()
-	defer client.Mutex.Unlock()
-
-	err := client.Conn.WriteJSON(message)
-	if err != nil {
-		log.Printf("Error sending message to %s: %v", client.Nickname, err)
-	}
+	client.Send(message)
 }
 
 // BroadcastMessage sends a message to all clients in a room
 func BroadcastMessage(room *core.Room, message map[string]interface{}) {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
+	room.LogEvent(core.Event{Kind: core.EventBroadcast, To: core.AudienceRoom, Message: messageBytes})
+	droppable := core.IsDroppable(message)
 	for client := range room.AllClients {
-		SendMessage(client, message)
+		client.SendRaw(messageBytes, droppable)
 	}
 }
 
@@ -92,22 +112,12 @@ func broadcastPlayerListUpdate(room *core.Room) {
 
 	// Add host if exists
 	if room.HostClient != nil {
-		players = append(players, core.Player{
-			Nickname: room.HostClient.Nickname,
-			ID:       room.HostClient.Nickname, // Use nickname as ID for simplicity
-			IsHost:   true,
-			Score:    room.HostClient.Score,
-		})
+		players = append(players, room.HostClient.Player())
 	}
 
 	// Add player clients
 	for client := range room.PlayerClients {
-		players = append(players, core.Player{
-			Nickname: client.Nickname,
-			ID:       client.Nickname,
-			IsHost:   false,
-			Score:    client.Score,
-		})
+		players = append(players, client.Player())
 	}
 
 	// Send platform message for player list updates
@@ -138,18 +148,19 @@ func broadcastPlayerListUpdate(room *core.Room) {
 }
 
 // handleNotifyPlatformPlayers handles platform player notification
-func handleNotifyPlatformPlayers(client *core.Client, room *core.Room, msg map[string]interface{}) {
+func handleNotifyPlatformPlayers(room *core.Room, client *core.Client, message core.Message) error {
 	log.Printf("[DEBUG] handleNotifyPlatformPlayers called by %s in room %s", client.Nickname, room.ID)
-	
-	// Extract message and game type
-	message, _ := msg["message"].(string)
-	gameType, _ := msg["gameType"].(string)
+	payload := message.Data.(*NotifyPlayersPayload)
+	gameType := payload.GameType
+	if gameType == "" {
+		gameType = room.GameType
+	}
 	
 	// Create platform notification message
 	notificationMsg := map[string]interface{}{
 		"type": "platformNotification",
 		"data": map[string]interface{}{
-			"message":  message,
+			"message":  payload.Message,
 			"gameType": gameType,
 			"roomId":   room.ID,
 		},
@@ -158,109 +169,102 @@ func handleNotifyPlatformPlayers(client *core.Client, room *core.Room, msg map[s
 	// Broadcast notification to all clients in the room
 	BroadcastMessage(room, notificationMsg)
 	
-	// Use the hostStartGame router to handle game start
-	coreMsg := core.Message{
-		Type: "hostStartGame",
-		Data: msg,
-	}
-	HandleHostStartGameRouter(room, client, coreMsg)
+	// Start the game named in the notification
+	return StartGame(room, client, gameType, message)
 }
 
 // handleStartGameWithNotification handles starting game with notification
-func handleStartGameWithNotification(client *core.Client, room *core.Room, msg map[string]interface{}) {
+func handleStartGameWithNotification(room *core.Room, client *core.Client, message core.Message) error {
 	log.Printf("[WEBSOCKET] %s starting game with notification in room %s", client.Nickname, room.ID)
 	
-	// Check if client is host
-	if !client.IsHost {
-		log.Printf("[WEBSOCKET] Non-host %s tried to start game in room %s", client.Nickname, room.ID)
-		return
-	}
-	
 	// Use the hostStartGame router to handle game start
-	coreMsg := core.Message{
-		Type: "hostStartGame",
-		Data: msg,
+	return HandleHostStartGameRouter(room, client, message)
+}
+
+// handleHostCloseGame handles host closing game for different game types
+func handleHostCloseGame(room *core.Room, client *core.Client, message core.Message) error {
+	log.Printf("[WEBSOCKET] Host %s closing game in room %s", client.Nickname, room.ID)
+
+	// Closing the game also abandons a running tournament
+	if room.Tournament.Running() {
+		room.Tournament.Cancel()
+		log.Printf("[WEBSOCKET] Tournament cancelled in room %s", room.ID)
 	}
-	HandleHostStartGameRouter(room, client, coreMsg)
+
+	// End the active game and reset room state
+	EndGame(room)
+	room.GameStarted = false
+	room.Paused = false
+	room.Game = nil
+	log.Printf("[WEBSOCKET] Game closed in room %s", room.ID)
+	return nil
 }
 
-// handleScoreUpdate handles score updates for different game types
-func handleScoreUpdate(client *core.Client, room *core.Room, msg map[string]interface{}) {
-	log.Printf("[WEBSOCKET] Score update from %s in room %s", client.Nickname, room.ID)
-	
-	// Try to find a registered handler for scoreUpdate
-	if handler, exists := GetHandler("scoreUpdate"); exists {
-		coreMsg := core.Message{
-			Type: "scoreUpdate",
-			Data: msg,
-		}
-		handler(room, client, coreMsg)
-		return
+// requireRunningGame reports an error unless the room has a game in progress
+func requireRunningGame(room *core.Room) error {
+	if room.Game == nil || !room.GameStarted || room.GameEnded {
+		return core.NewError(core.ErrCodeNoActiveGame, "no game is running in room %s", room.ID)
 	}
-	
-	// Try game-specific score update handlers
-	gameType := determineGameType(room, msg)
-	scoreUpdateType := gameType + "ScoreUpdate"
-	
-	if handler, exists := GetHandler(scoreUpdateType); exists {
-		coreMsg := core.Message{
-			Type: scoreUpdateType,
-			Data: msg,
-		}
-		handler(room, client, coreMsg)
-	} else {
-		log.Printf("[WEBSOCKET] No handler found for score update type: %s", scoreUpdateType)
+	return nil
+}
+
+// broadcastGameState tells everyone in the room that the host paused, resumed or stopped the game
+func broadcastGameState(room *core.Room, msgType string) {
+	message := map[string]interface{}{
+		"type":     msgType,
+		"gameType": room.Game.Type(),
+		"timeLeft": room.GameTime,
+	}
+	// Resuming moves deadlines, so clients get the game state again
+	if msgType == "gameResumed" {
+		message["snapshot"] = room.Game.Snapshot()
 	}
+	BroadcastMessage(room, message)
 }
 
-// handleHostCloseGame handles host closing game for different game types
-func handleHostCloseGame(client *core.Client, room *core.Room) {
-	log.Printf("[WEBSOCKET] Host %s closing game in room %s", client.Nickname, room.ID)
-	
-	// Check if there's a registered handler for gameEnd
-	if handler, exists := GetHandler("gameEnd"); exists {
-		coreMsg := core.Message{
-			Type: "gameEnd",
-			Data: map[string]interface{}{},
-		}
-		handler(room, client, coreMsg)
-	} else {
-		// Default behavior: reset room state
-		room.GameStarted = false
-		room.GameData = nil
-		log.Printf("[WEBSOCKET] Game closed in room %s", room.ID)
+// handleHostPauseGame freezes the running game's countdown and refuses game input until it is resumed
+func handleHostPauseGame(room *core.Room, client *core.Client, message core.Message) error {
+	if err := requireRunningGame(room); err != nil {
+		return err
 	}
+	if room.Paused {
+		return core.NewError(core.ErrCodeGamePaused, "the game in room %s is already paused", room.ID)
+	}
+
+	room.Pause()
+	log.Printf("[WEBSOCKET] Host %s paused %s in room %s with %d seconds left", client.Nickname, room.Game.Type(), room.ID, room.GameTime)
+	broadcastGameState(room, "gamePaused")
+	return nil
 }
 
-// determineGameType determines the current game type based on room state or message
-func determineGameType(room *core.Room, msg map[string]interface{}) string {
-	// Check message for game type hint
-	if msg != nil {
-		if gameType, exists := msg["gameType"].(string); exists {
-			return gameType
-		}
-		// Check for red envelope specific fields
-		if _, exists := msg["envelopeId"]; exists {
-			return "redenvelope"
-		}
-		if _, exists := msg["collectedCount"]; exists {
-			return "redenvelope"
-		}
+// handleHostResumeGame restarts a paused game's countdown and input
+func handleHostResumeGame(room *core.Room, client *core.Client, message core.Message) error {
+	if err := requireRunningGame(room); err != nil {
+		return err
+	}
+	if !room.Paused {
+		return core.NewError(core.ErrCodeInvalidMessage, "the game in room %s is not paused", room.ID)
 	}
 
-	// Default to memory game if no clear indicators
-	return "memory"
+	paused := room.Resume()
+	if resumer, ok := room.Game.(core.Resumer); ok {
+		resumer.OnResume(paused)
+	}
+	log.Printf("[WEBSOCKET] Host %s resumed %s in room %s after %s", client.Nickname, room.Game.Type(), room.ID, paused.Round(time.Millisecond))
+	broadcastGameState(room, "gameResumed")
+	return nil
 }
 
-// Helper function to safely extract int values from map
-func getIntFromMap(data map[string]interface{}, key string, defaultValue int) int {
-	if value, exists := data[key]; exists {
-		if intValue, ok := value.(float64); ok {
-			return int(intValue)
-		}
-		if intValue, ok := value.(int); ok {
-			return intValue
-		}
+// handleHostStopGame ends the running game early. The game is finalized and
+// recorded exactly as if its time had run out.
+func handleHostStopGame(room *core.Room, client *core.Client, message core.Message) error {
+	if err := requireRunningGame(room); err != nil {
+		return err
 	}
-	return defaultValue
+
+	room.Resume()
+	log.Printf("[WEBSOCKET] Host %s stopped %s in room %s with %d seconds left", client.Nickname, room.Game.Type(), room.ID, room.GameTime)
+	broadcastGameState(room, "gameStopped")
+	EndGame(room)
+	return nil
 }
@@ -0,0 +1,13 @@
+package message
+
+// StartGamePayload is the payload of platform level game start messages.
+// GameType defaults to the game the room was created for.
+type StartGamePayload struct {
+	GameType string `json:"gameType"`
+}
+
+// NotifyPlayersPayload is the payload of notifyPlatformPlayers messages
+type NotifyPlayersPayload struct {
+	Message  string `json:"message"`
+	GameType string `json:"gameType"`
+}
@@ -7,19 +7,36 @@ import (
 	"gaming-platform/core/interfaces"
 )
 
+// HandlerRegistration pairs a message handler with the payload struct it expects
+type HandlerRegistration struct {
+	Handler  interfaces.MessageHandler
+	Payload  interface{} // Payload prototype decoded before dispatch, nil for none
+	HostOnly bool        // Rejected by the router unless sent by the host
+}
+
 // HandlerRegistry manages message type to handler mappings
 type HandlerRegistry struct {
-	handlers map[string]interfaces.MessageHandler
+	handlers map[string]HandlerRegistration
 	mutex    sync.RWMutex
 }
 
 // Global registry instance
 var registry = &HandlerRegistry{
-	handlers: make(map[string]interfaces.MessageHandler),
+	handlers: make(map[string]HandlerRegistration),
+}
+
+// RegisterHandler registers a handler and its payload prototype for a specific message type
+func RegisterHandler(msgType string, handler interfaces.MessageHandler, payload interface{}) {
+	register(msgType, HandlerRegistration{Handler: handler, Payload: payload})
 }
 
-// RegisterHandler registers a handler for a specific message type
-func RegisterHandler(msgType string, handler interfaces.MessageHandler) {
+// RegisterHostHandler registers a handler for a message type that only the host may send
+func RegisterHostHandler(msgType string, handler interfaces.MessageHandler, payload interface{}) {
+	register(msgType, HandlerRegistration{Handler: handler, Payload: payload, HostOnly: true})
+}
+
+// register stores a handler registration for a message type
+func register(msgType string, registration HandlerRegistration) {
 	registry.mutex.Lock()
 	defer registry.mutex.Unlock()
 	
@@ -27,17 +44,17 @@ func RegisterHandler(msgType string, handler interfaces.MessageHandler) {
 		log.Printf("[REGISTRY] Warning: Overwriting existing handler for message type: %s", msgType)
 	}
 	
-	registry.handlers[msgType] = handler
-	log.Printf("[REGISTRY] Registered handler for message type: %s", msgType)
+	registry.handlers[msgType] = registration
+	log.Printf("[REGISTRY] Registered handler for message type: %s (host only: %t)", msgType, registration.HostOnly)
 }
 
-// GetHandler retrieves a handler for a specific message type
-func GetHandler(msgType string) (interfaces.MessageHandler, bool) {
+// GetHandler retrieves the handler registration for a specific message type
+func GetHandler(msgType string) (HandlerRegistration, bool) {
 	registry.mutex.RLock()
 	defer registry.mutex.RUnlock()
 	
-	handler, exists := registry.handlers[msgType]
-	return handler, exists
+	registration, exists := registry.handlers[msgType]
+	return registration, exists
 }
 
 // UnregisterHandler removes a handler for a specific message type
@@ -59,4 +76,4 @@ func ListRegisteredTypes() []string {
 		types = append(types, msgType)
 	}
 	return types
-}
\ No newline at end of file
+}
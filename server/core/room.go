@@ -0,0 +1,200 @@
+package core
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// roomInboxSize is the number of events buffered for a room's event loop
+const roomInboxSize = 256
+
+// NewRoom creates a room and starts its event loop
+func NewRoom(roomID string) *Room {
+	room := &Room{
+		ID:                roomID,
+		HostClient:        nil,
+		PlayerClients:     make(map[*Client]bool), // Only non-host players
+		SpectatorClients:  make(map[*Client]bool),
+		AllClients:        make(map[*Client]bool), // All clients for backward compatibility
+		GameTime:          0,
+		TotalPlayers:      0,
+		PlayersReady:      make(map[string]bool),
+		WaitingForPlayers: true,
+		GameStarted:       false,
+		GameEnded:         false,
+		inbox:             make(chan func(), roomInboxSize),
+		done:              make(chan struct{}),
+	}
+	go room.run()
+	return room
+}
+
+// run processes room events one at a time until the room is stopped
+func (r *Room) run() {
+	log.Printf("[ROOM %s] Event loop started", r.ID)
+	for {
+		select {
+		case event := <-r.inbox:
+			event()
+			if r.stopping {
+				close(r.done)
+				log.Printf("[ROOM %s] Event loop stopped", r.ID)
+				return
+			}
+		}
+	}
+}
+
+// Post queues an event for the room's event loop without waiting for it to run.
+// It returns false if the room has been stopped.
+func (r *Room) Post(event func()) bool {
+	// A stopped room may still have space in its inbox, which select could pick
+	select {
+	case <-r.done:
+		return false
+	default:
+	}
+	select {
+	case r.inbox <- event:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// Do runs an event on the room's event loop and waits until it has finished.
+// It must not be called from the event loop itself. It returns false if the
+// room was stopped before the event ran.
+func (r *Room) Do(event func()) bool {
+	finished := make(chan struct{})
+	if !r.Post(func() {
+		defer close(finished)
+		event()
+	}) {
+		return false
+	}
+
+	select {
+	case <-finished:
+		return true
+	case <-r.done:
+		// The event may have stopped the room itself
+		select {
+		case <-finished:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// Every runs fn on the room's event loop at the given interval until the
+// returned stop function is called. Stop must be called from the event loop.
+// Every drives game timers, so ticks are skipped while the game is paused.
+func (r *Room) Every(interval time.Duration, fn func()) (stop func()) {
+	ticker := time.NewTicker(interval)
+	quit := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.Post(func() {
+					// Drop ticks that were queued before the timer was stopped
+					select {
+					case <-quit:
+						return
+					default:
+					}
+					if r.Paused {
+						return
+					}
+					fn()
+				})
+			case <-quit:
+				return
+			case <-r.done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() {
+			close(quit)
+		})
+	}
+}
+
+// After runs fn once on the room's event loop after the given delay unless the
+// returned stop function is called first. Stop must be called from the event loop.
+func (r *Room) After(delay time.Duration, fn func()) (stop func()) {
+	quit := make(chan struct{})
+	var once sync.Once
+
+	timer := time.AfterFunc(delay, func() {
+		r.Post(func() {
+			// Drop the event if the timer was stopped after it fired
+			select {
+			case <-quit:
+				return
+			default:
+			}
+			fn()
+		})
+	})
+
+	return func() {
+		once.Do(func() {
+			close(quit)
+			timer.Stop()
+		})
+	}
+}
+
+// Stop stops the room's event loop once the current event has finished.
+// It must be called from the event loop; pending events are discarded.
+func (r *Room) Stop() {
+	r.stopping = true
+}
+
+// Stopped reports whether the room's event loop has been stopped
+func (r *Room) Stopped() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Pause freezes the running game. It must be called on the event loop.
+func (r *Room) Pause() {
+	r.Paused = true
+	r.PausedAt = time.Now()
+}
+
+// Resume unfreezes the running game and returns how long it was paused.
+// It must be called on the event loop.
+func (r *Room) Resume() time.Duration {
+	if !r.Paused {
+		return 0
+	}
+	paused := time.Since(r.PausedAt)
+	r.Paused = false
+	r.PausedFor += paused
+	return paused
+}
+
+// PlayTime returns how long the running game, started at startedAt, has been
+// played, leaving out the time it spent paused
+func (r *Room) PlayTime(startedAt time.Time) time.Duration {
+	played := time.Since(startedAt) - r.PausedFor
+	if r.Paused {
+		played -= time.Since(r.PausedAt)
+	}
+	return played
+}
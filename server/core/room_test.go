@@ -0,0 +1,119 @@
+package core
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDoRunsEventsOneAtATime(t *testing.T) {
+	room := NewRoom("TEST01")
+	defer room.Do(room.Stop)
+
+	// Unsynchronized increments are only safe because the loop serializes them
+	counter := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				room.Do(func() { counter++ })
+			}
+		}()
+	}
+	wg.Wait()
+
+	var got int
+	room.Do(func() { got = counter })
+	if got != 1000 {
+		t.Fatalf("counter = %d, want 1000", got)
+	}
+}
+
+func TestPostKeepsOrder(t *testing.T) {
+	room := NewRoom("TEST02")
+	defer room.Do(room.Stop)
+
+	var order []int
+	for i := 0; i < 100; i++ {
+		i := i
+		room.Post(func() { order = append(order, i) })
+	}
+
+	var got []int
+	room.Do(func() { got = append(got, order...) })
+	for i, value := range got {
+		if value != i {
+			t.Fatalf("event %d ran as %d", i, value)
+		}
+	}
+	if len(got) != 100 {
+		t.Fatalf("ran %d events, want 100", len(got))
+	}
+}
+
+func TestStoppedRoomRefusesEvents(t *testing.T) {
+	room := NewRoom("TEST03")
+	if !room.Do(room.Stop) {
+		t.Fatal("Do(Stop) = false, want true")
+	}
+	if !room.Stopped() {
+		t.Fatal("Stopped() = false after Stop")
+	}
+	if room.Post(func() {}) {
+		t.Fatal("Post on a stopped room = true, want false")
+	}
+	if room.Do(func() { t.Error("event ran on a stopped room") }) {
+		t.Fatal("Do on a stopped room = true, want false")
+	}
+}
+
+func TestEveryStops(t *testing.T) {
+	room := NewRoom("TEST04")
+	defer room.Do(room.Stop)
+
+	ticks := 0
+	var stop func()
+	room.Do(func() {
+		stop = room.Every(5*time.Millisecond, func() {
+			ticks++
+			if ticks == 3 {
+				stop()
+			}
+		})
+	})
+
+	time.Sleep(60 * time.Millisecond)
+	var got int
+	room.Do(func() { got = ticks })
+	if got != 3 {
+		t.Fatalf("ticks = %d, want 3", got)
+	}
+}
+
+func TestAfterCanBeCancelled(t *testing.T) {
+	room := NewRoom("TEST05")
+	defer room.Do(room.Stop)
+
+	fired := make(chan string, 2)
+	room.Do(func() {
+		room.After(5*time.Millisecond, func() { fired <- "kept" })
+		stop := room.After(5*time.Millisecond, func() { fired <- "cancelled" })
+		stop()
+	})
+
+	select {
+	case name := <-fired:
+		if name != "kept" {
+			t.Fatalf("%s timer fired", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timer did not fire")
+	}
+	select {
+	case name := <-fired:
+		t.Fatalf("%s timer fired too", name)
+	case <-time.After(30 * time.Millisecond):
+	}
+}
@@ -0,0 +1,22 @@
+package core
+
+import "time"
+
+// Score audit actions
+const (
+	ScoreRejected = "rejected" // The report was refused and the score left as it was
+	ScoreClamped  = "clamped"  // A lower score than reported was accepted
+)
+
+// ScoreAuditEntry records a client-reported score that failed validation
+type ScoreAuditEntry struct {
+	Time     time.Time `json:"time"`
+	GameType string    `json:"gameType"`
+	PlayerID string    `json:"playerId"`
+	Nickname string    `json:"nickname"`
+	Previous int       `json:"previous"` // Score before the report
+	Reported int       `json:"reported"`
+	Accepted int       `json:"accepted"` // Score kept after the report
+	Action   string    `json:"action"`
+	Reason   string    `json:"reason"`
+}
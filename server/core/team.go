@@ -0,0 +1,21 @@
+package core
+
+// Team scoring modes
+const (
+	TeamScoringSum     = "sum"     // A team scores the sum of its players' scores
+	TeamScoringAverage = "average" // A team scores the average of its players' scores
+)
+
+// TeamSettings configures team play in a room
+type TeamSettings struct {
+	Names   []string `json:"names" binding:"min=2,max=10,dive,required,max=32"`
+	Scoring string   `json:"scoring" binding:"omitempty,oneof=sum average"`
+}
+
+// TeamStanding is a team's combined score in the current game
+type TeamStanding struct {
+	Team    string  `json:"team"`
+	Score   float64 `json:"score"`
+	Players int     `json:"players"`
+	Rank    int     `json:"rank"`
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Tournament is a playlist of games run back to back in one room.
+// Each round's scores are normalized to points that add up across rounds.
+type Tournament struct {
+	Rounds       []TournamentRound    `json:"rounds"`
+	Current      int                  `json:"current"`      // Index of the running or next round
+	BreakSeconds int                  `json:"breakSeconds"` // Pause between rounds
+	Finished     bool                 `json:"finished"`
+	Standings    map[string]*Standing `json:"-"` // Keyed by player ID
+	StopBreak    func()               `json:"-"` // Cancels the timer starting the next round
+}
+
+// TournamentRound is one game of a tournament playlist
+type TournamentRound struct {
+	GameType string          `json:"gameType"`
+	Settings json.RawMessage `json:"settings,omitempty"`
+	ResultID string          `json:"resultId,omitempty"` // Recorded result once the round has finished
+}
+
+// Standing is a player's cumulative tournament score
+type Standing struct {
+	PlayerID string `json:"playerId"`
+	Nickname string `json:"nickname"`
+	Points   int    `json:"points"`
+	Rounds   []int  `json:"rounds"` // Points earned in each finished round
+	Rank     int    `json:"rank"`
+}
+
+// Running reports whether the tournament still has rounds to play
+func (t *Tournament) Running() bool {
+	return t != nil && !t.Finished
+}
+
+// Cancel ends the tournament without playing the remaining rounds
+func (t *Tournament) Cancel() {
+	if t.StopBreak != nil {
+		t.StopBreak()
+		t.StopBreak = nil
+	}
+	t.Finished = true
+}
+
+// Table returns the standings ranked by points
+func (t *Tournament) Table() []Standing {
+	table := make([]Standing, 0, len(t.Standings))
+	for _, standing := range t.Standings {
+		table = append(table, *standing)
+	}
+	sort.Slice(table, func(i, j int) bool {
+		if table[i].Points != table[j].Points {
+			return table[i].Points > table[j].Points
+		}
+		return table[i].Nickname < table[j].Nickname
+	})
+	for i := range table {
+		table[i].Rank = i + 1
+	}
+	return table
+}
@@ -2,22 +2,57 @@
 package core
 
 import (
+	"encoding/json"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// Role is what a connection is allowed to do in a room
+type Role string
+
+const (
+	// RoleHost runs the room and may send host-only messages
+	RoleHost Role = "host"
+	// RolePlayer plays the room's games
+	RolePlayer Role = "player"
+	// RoleSpectator watches the room's games without playing or scoring
+	RoleSpectator Role = "spectator"
+)
+
+// Valid reports whether the role is a known role
+func (r Role) Valid() bool {
+	return r == RoleHost || r == RolePlayer || r == RoleSpectator
+}
+
 // Client represents a connected player
 type Client struct {
 	Conn         *websocket.Conn `json:"-"`
+	ID           string          `json:"id"`               // Stable player ID carried by the session token
+	UserID       string          `json:"userId,omitempty"` // Registered user, empty for guests
 	Nickname     string          `json:"nickname"`
 	RoomID       string          `json:"roomId"`
 	IsHost       bool            `json:"isHost"`
 	Score        int             `json:"score"`
 	Avatar       string          `json:"avatar"`
 	GameFinished bool            `json:"gameFinished"`
+	Away         bool            `json:"away"`                  // Disconnected but holding the seat during the grace period
+	Team         string          `json:"team,omitempty"`        // Team the player plays for, empty outside team play
+	IsSpectator  bool            `json:"isSpectator,omitempty"` // Receives broadcasts but neither plays nor scores
 	Mutex        sync.RWMutex    `json:"-"`
+
+	// Stops the grace period timer while the client is away; owned by the room's event loop
+	StopGrace func() `json:"-"`
+
+	// Outbound queue drained by the client's write pump
+	send        chan outboundMessage
+	sendMutex   sync.Mutex
+	options     SendOptions
+	done        chan struct{}
+	closeOnce   sync.Once
+	closeReason DisconnectReason
 }
 
 // GameSettings represents game configuration
@@ -26,46 +61,73 @@ type GameSettings struct {
 	GameDuration int `json:"gameDuration"`
 }
 
-// ReconnectionRequest represents a reconnection operation
-type ReconnectionRequest struct {
-	Client   *Client
-	Response chan bool
-}
-
-// Room represents a game room with separated host and player storage
+// Room represents a game room with separated host and player storage.
+// All fields are owned by the room's event loop; other goroutines must go
+// through Do or Post instead of touching them directly.
 type Room struct {
-	ID                string                   `json:"id"`
+	ID string `json:"id"`
 	// Separated storage for host and players
-	HostClient        *Client                  `json:"hostClient,omitempty"`
-	PlayerClients     map[*Client]bool         `json:"-"` // Only non-host players
-	AllClients        map[*Client]bool         `json:"-"` // All clients for backward compatibility
-	GameTime          int                      `json:"gameTime"`
-	TotalPlayers      int                      `json:"totalPlayers"`
-	PlayersReady      map[string]bool          `json:"playersReady"`
-	WaitingForPlayers bool                     `json:"waitingForPlayers"`
-	GameStarted       bool                     `json:"gameStarted"`
-	GameEnded         bool                     `json:"gameEnded"`
-	Timer             *time.Ticker             `json:"-"`
-	ReconnectionChan  chan ReconnectionRequest `json:"-"`
-	StopChan          chan bool                `json:"-"`
-	Mutex             sync.RWMutex             `json:"-"`
-	// Game-specific data will be handled by game modules
-	GameData interface{} `json:"gameData,omitempty"`
+	HostClient        *Client          `json:"hostClient,omitempty"`
+	PlayerClients     map[*Client]bool `json:"-"` // Only non-host players
+	SpectatorClients  map[*Client]bool `json:"-"` // Watching without playing
+	AllClients        map[*Client]bool `json:"-"` // All clients for backward compatibility
+	GameTime          int              `json:"gameTime"`
+	TotalPlayers      int              `json:"totalPlayers"` // Host and players, not spectators
+	PlayersReady      map[string]bool  `json:"playersReady"`
+	WaitingForPlayers bool             `json:"waitingForPlayers"`
+	GameStarted       bool             `json:"gameStarted"`
+	GameEnded         bool             `json:"gameEnded"`
+	Paused            bool             `json:"paused"` // The host froze the running game's timers and input
+	PausedAt          time.Time        `json:"-"`      // When the current pause began
+	PausedFor         time.Duration    `json:"-"`      // Time the running game spent in earlier pauses
+	// Live game instance created by the registered game factory
+	Game Game `json:"-"`
+	// Playlist of games run back to back, nil outside tournaments
+	Tournament *Tournament `json:"-"`
+	// Team play configuration, nil when players play individually
+	Teams *TeamSettings `json:"teams,omitempty"`
+	// Score reports the validators refused or clamped, oldest first
+	ScoreAudit []ScoreAuditEntry `json:"-"`
+	// Random source seeded with Seed; avatars and game seeds are drawn from it
+	Rand *rand.Rand `json:"-"`
+	// Event log of the running game, handed to the results once it ends
+	EventLog *EventLog `json:"-"`
+
+	// Set when the room is created and never changed afterwards, so they may
+	// be read without going through the event loop
+	GameType   string          `json:"gameType"`           // Game the room was created for
+	Settings   json.RawMessage `json:"settings,omitempty"` // Default start settings for the game
+	Capacity   int             `json:"capacity"`           // Maximum number of players, zero for unlimited
+	HostSecret string          `json:"-"`                  // Grants host rights to whoever presents it
+	Seed       int64           `json:"-"`                  // Seeds Rand, so a room replays the same from the same seed
+
+	// Event loop inbox processing joins, leaves, messages and timer ticks
+	inbox    chan func()
+	done     chan struct{}
+	stopping bool
 }
 
-// Message represents a WebSocket message
+// Message represents a versioned WebSocket message envelope
 type Message struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data,omitempty"`
+	Type    string          `json:"type"`
+	Version int             `json:"version,omitempty"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// Data holds the decoded payload struct after the router has validated it
+	Data interface{} `json:"-"`
 }
 
 // Player represents player information for API responses
 type Player struct {
-	Nickname string `json:"nickname"`
-	ID       string `json:"id"`
-	IsHost   bool   `json:"isHost"`
-	Score    int    `json:"score"`
-	Avatar   string `json:"avatar"`
+	Nickname    string `json:"nickname"`
+	ID          string `json:"id"`
+	UserID      string `json:"userId,omitempty"`
+	IsHost      bool   `json:"isHost"`
+	Score       int    `json:"score"`
+	Avatar      string `json:"avatar"`
+	Away        bool   `json:"away"`
+	Team        string `json:"team,omitempty"`
+	IsSpectator bool   `json:"isSpectator,omitempty"`
 }
 
 // PlayerListResponse represents the response for player list API
@@ -76,8 +138,48 @@ type PlayerListResponse struct {
 // RoomInfoResponse represents the response for room info API
 type RoomInfoResponse struct {
 	RoomID            string `json:"roomId"`
+	GameType          string `json:"gameType"`
+	Capacity          int    `json:"capacity"`
 	TotalPlayers      int    `json:"totalPlayers"`
+	Spectators        int    `json:"spectators"`
 	WaitingForPlayers bool   `json:"waitingForPlayers"`
 	GameStarted       bool   `json:"gameStarted"`
 	GameEnded         bool   `json:"gameEnded"`
 }
+
+// CreateRoomRequest represents the request body for the create room API
+type CreateRoomRequest struct {
+	GameType string          `json:"gameType" binding:"required"`
+	Settings json.RawMessage `json:"settings"`
+	Capacity int             `json:"capacity" binding:"min=0,max=500"`
+	Teams    *TeamSettings   `json:"teams"`
+	Seed     int64           `json:"seed"` // Seeds the room's random draws; zero picks one
+}
+
+// CreateRoomResponse represents the response for the create room API
+type CreateRoomResponse struct {
+	RoomID     string          `json:"roomId"`
+	JoinCode   string          `json:"joinCode"`
+	HostSecret string          `json:"hostSecret"`
+	GameType   string          `json:"gameType"`
+	Settings   json.RawMessage `json:"settings,omitempty"`
+	Capacity   int             `json:"capacity"`
+	Teams      *TeamSettings   `json:"teams,omitempty"`
+	Seed       int64           `json:"seed"`
+}
+
+// JoinRoomRequest represents the request body for the join room API
+type JoinRoomRequest struct {
+	Nickname     string `json:"nickname" binding:"max=32"`
+	HostSecret   string `json:"hostSecret"`   // Joins as host when it matches the room's secret
+	SessionToken string `json:"sessionToken"` // Resumes a previous seat in the room
+	Spectator    bool   `json:"spectator"`    // Watches without playing; ignored for the host
+}
+
+// JoinRoomResponse represents the response for the join room API
+type JoinRoomResponse struct {
+	AccessToken string `json:"accessToken"`
+	RoomID      string `json:"roomId"`
+	PlayerID    string `json:"playerId"`
+	Role        Role   `json:"role"`
+}
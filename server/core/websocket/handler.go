@@ -2,13 +2,17 @@
 package websocket
 
 import (
+	"errors"
 	"log"
+	"net"
 	"net/http"
+	"time"
 
+	"gaming-platform/config"
 	"gaming-platform/core"
 	"gaming-platform/core/message"
+	"gaming-platform/platform/auth"
 	"gaming-platform/platform/room"
-	"gaming-platform/utils"
 
 	"github.com/gorilla/websocket"
 )
@@ -25,6 +29,25 @@ var upgrader = websocket.Upgrader{
 func HandleWebSocketConnection(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[WEBSOCKET] New WebSocket connection attempt from %s", r.RemoteAddr)
 
+	// Connections must present an access token issued by the join API
+	claims, err := auth.VerifyAccessToken(r.URL.Query().Get("token"))
+	if err != nil {
+		log.Printf("[WEBSOCKET] Refusing %s: %v", r.RemoteAddr, err)
+		http.Error(w, "valid access token required", http.StatusUnauthorized)
+		return
+	}
+	roomID := claims.RoomID
+	nickname := claims.Nickname
+
+	gameRoom, exists := room.GetRoom(roomID)
+	if !exists {
+		log.Printf("[WEBSOCKET] Refusing %s: room %s does not exist", r.RemoteAddr, roomID)
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	log.Printf("[WEBSOCKET] Connection details - RoomID: %s, Nickname: %s, PlayerID: %s, Role: %s",
+		roomID, nickname, claims.PlayerID, claims.Role)
+
 	// Upgrade HTTP connection to WebSocket
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -34,58 +57,125 @@ func HandleWebSocketConnection(w http.ResponseWriter, r *http.Request) {
 	defer conn.Close()
 	log.Printf("[WEBSOCKET] WebSocket connection successfully upgraded from %s", r.RemoteAddr)
 
-	// Extract parameters from query string
-	query := r.URL.Query()
-	log.Printf("[WEBSOCKET] Query parameters: %v", query)
-
-	roomID := query.Get("roomId")
-	originalRoomID := roomID
-	if roomID == "" {
-		roomID = "default"
-		log.Printf("[WEBSOCKET] No roomId provided, using default room")
-	}
-
-	nickname := query.Get("nickname")
-	originalNickname := nickname
-	if nickname == "" {
-		nickname = "Anonymous"
-		log.Printf("[WEBSOCKET] No nickname provided, using Anonymous")
-	}
+	// Create client with its outbound queue
+	cfg := config.Get()
+	client := core.NewClient(conn, core.SendOptions{
+		QueueSize:    cfg.SendQueueSize,
+		Policy:       core.ParseOverflowPolicy(cfg.OverflowPolicy),
+		WriteWait:    cfg.WriteWait,
+		PingInterval: cfg.PingInterval,
+	})
+	client.ID = claims.PlayerID
+	client.UserID = claims.UserID
+	client.Nickname = nickname
+	client.RoomID = roomID
+	client.IsHost = claims.Role == core.RoleHost
+	client.IsSpectator = claims.Role == core.RoleSpectator
+	client.Score = 0
+	client.Avatar = claims.Avatar
+
+	// Drop peers that stop answering pings or send oversized messages
+	conn.SetReadLimit(cfg.MaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	})
 
-	isHost := query.Get("isHost") == "true"
-	log.Printf("[WEBSOCKET] Connection details - RoomID: %s (original: %s), Nickname: %s (original: %s), IsHost: %t",
-		roomID, originalRoomID, nickname, originalNickname, isHost)
-
-	// Create client
-	client := &core.Client{
-		Conn:     conn,
-		Nickname: nickname,
-		RoomID:   roomID,
-		IsHost:   isHost,
-		Score:    0,
-		Avatar:   utils.GetRandomAvatar(),
+	// Register client to room
+	if err := room.RegisterClient(gameRoom, client); err != nil {
+		log.Printf("[WEBSOCKET] Refusing %s in room %s: %v", nickname, roomID, err)
+		refuseJoin(conn, err)
+		return
 	}
-
-	// Get or create room
-	gameRoom := room.GetOrCreateRoom(roomID)
 	log.Printf("[WEBSOCKET] Got room %s for client %s", roomID, nickname)
-
-	// Register client to room
-	room.RegisterClient(gameRoom, client)
+	client.StartWritePump()
+	sendSession(client, roomID)
 
 	// Handle client messages
+	var reason core.DisconnectReason
 	for {
 		_, msgData, err := conn.ReadMessage()
 		if err != nil {
-			log.Printf("[WEBSOCKET] Read error from %s: %v", nickname, err)
+			reason = disconnectReason(client, err)
+			log.Printf("[WEBSOCKET] Read error from %s (%s): %v", nickname, reason, err)
+			break
+		}
+
+		// Any message proves the peer is alive
+		conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+
+		// Handle the message on the room's event loop
+		if !gameRoom.Do(func() {
+			message.HandleMessage(client, gameRoom, msgData)
+		}) {
+			reason = core.DisconnectClosed
+			log.Printf("[WEBSOCKET] Room %s closed while %s was connected", roomID, nickname)
 			break
 		}
+	}
+
+	// Keep the seat for the reconnection grace period
+	room.DisconnectClient(gameRoom, client, reason, cfg.ReconnectGrace)
+	client.Close(reason)
+	log.Printf("[WEBSOCKET] Client %s disconnected from room %s (%s)", nickname, roomID, reason)
+}
+
+// refuseJoin tells a client why it could not join before closing the connection.
+// The write pump is not running yet, so the message is written directly.
+func refuseJoin(conn *websocket.Conn, err error) {
+	code := core.ErrCodeRoomClosed
+	if errors.Is(err, room.ErrRoomFull) {
+		code = core.ErrCodeRoomFull
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(config.Get().WriteWait))
+	conn.WriteJSON(map[string]interface{}{
+		"type": "error",
+		"data": map[string]interface{}{
+			"code":    code,
+			"message": err.Error(),
+		},
+	})
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()))
+}
+
+// sendSession tells the client its stable player ID and the token that resumes its seat
+func sendSession(client *core.Client, roomID string) {
+	token, err := auth.IssueSessionToken(client.ID, roomID)
+	if err != nil {
+		log.Printf("[WEBSOCKET] Failed to issue session token for %s: %v", client.Nickname, err)
+		return
+	}
+
+	client.Send(map[string]interface{}{
+		"type": "session",
+		"data": map[string]interface{}{
+			"playerId":     client.ID,
+			"sessionToken": token,
+			"roomId":       roomID,
+		},
+	})
+}
+
+// disconnectReason classifies why a client's read loop ended
+func disconnectReason(client *core.Client, err error) core.DisconnectReason {
+	// The server closed the connection itself, e.g. after a queue overflow
+	if reason := client.CloseReason(); reason != "" {
+		return reason
+	}
+
+	if errors.Is(err, websocket.ErrReadLimit) {
+		return core.DisconnectMessageTooLarge
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return core.DisconnectTimeout
+	}
 
-		// Handle the message
-		message.HandleMessage(client, gameRoom, msgData)
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+		return core.DisconnectClosed
 	}
 
-	// Unregister client when connection closes
-	room.UnregisterClient(gameRoom, client)
-	log.Printf("[WEBSOCKET] Client %s disconnected from room %s", nickname, roomID)
+	return core.DisconnectReadError
 }
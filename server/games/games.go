@@ -0,0 +1,10 @@
+// Package games links every game module into the platform.
+// Each game registers itself with the message router from its init function,
+// so adding a new game only requires a blank import here.
+package games
+
+import (
+	_ "gaming-platform/games/memory"
+	_ "gaming-platform/games/redenvelope"
+	_ "gaming-platform/games/whackmole"
+)
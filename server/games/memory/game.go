@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"gaming-platform/core"
+	"gaming-platform/platform/room"
+)
+
+// GameType is the registered type name of the memory game
+const GameType = "memory"
+
+// Game is a memory game instance owned by a room
+type Game struct {
+	room      *core.Room
+	data      GameData
+	settings  GameSettings
+	ended     bool
+	boards    map[string]*Board // Authoritative decks keyed by player ID
+	rng       *rand.Rand        // Seeded with settings.Seed
+	startedAt time.Time
+	stopTimer func()
+}
+
+// NewGame creates a memory game bound to the given room
+func NewGame(gameRoom *core.Room) core.Game {
+	return &Game{
+		room: gameRoom,
+	}
+}
+
+// Type returns the game type
+func (g *Game) Type() string {
+	return GameType
+}
+
+// OnPlayerJoin sends the running game settings to a late joining player
+func (g *Game) OnPlayerJoin(client *core.Client) {
+	if !g.room.GameStarted || g.ended || client.IsHost {
+		return
+	}
+
+	room.SendToClient(client, map[string]interface{}{
+		"type":     "platformGameStarted",
+		"gameType": GameType,
+		"gameData": ClientGameData{
+			GameSettings: g.settings,
+			GameTime:     g.room.GameTime,
+		},
+		"message": "Memory game started!",
+	})
+}
+
+// OnPlayerLeave refreshes the host leaderboard after a player leaves
+func (g *Game) OnPlayerLeave(client *core.Client) {
+	if g.ended || client.IsHost || client.IsSpectator {
+		return
+	}
+	sendPlayerLeaderboardToHost(g.room)
+}
+
+// Snapshot returns the current memory game state
+func (g *Game) Snapshot() interface{} {
+	snapshot := Snapshot{
+		GameType: GameType,
+		Settings: g.settings,
+		TimeLeft: g.room.GameTime,
+		Ended:    g.ended,
+		Scores:   g.scores(),
+	}
+	if g.settings.Authoritative {
+		snapshot.Boards = make(map[string]BoardView, len(g.boards))
+		for playerID, board := range g.boards {
+			snapshot.Boards[playerID] = board.view()
+		}
+	}
+	return snapshot
+}
+
+// PlayerSnapshot adds the resuming player's face-up card to the snapshot, so
+// their next flip pairs with a card they can see
+func (g *Game) PlayerSnapshot(client *core.Client) interface{} {
+	snapshot := g.Snapshot().(Snapshot)
+	if board, exists := g.boards[client.ID]; exists && len(board.FlippedCards) > 0 {
+		snapshot.Flipped = append([]CardRef(nil), board.FlippedCards...)
+	}
+	return snapshot
+}
+
+// End stops the game timer and broadcasts the final scores
+func (g *Game) End() {
+	if g.ended {
+		return
+	}
+	g.stop()
+	g.handleGameEnd()
+}
+
+// stop stops the game timer
+func (g *Game) stop() {
+	if g.stopTimer != nil {
+		g.stopTimer()
+		log.Printf("[MEMORY] Timer stopped for room %s", g.room.ID)
+	}
+}
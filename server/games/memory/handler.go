@@ -5,99 +5,97 @@ import (
 	"log"
 
 	"gaming-platform/core"
-	"gaming-platform/core/interfaces"
+	"gaming-platform/core/message"
 	"gaming-platform/platform/room"
+	"gaming-platform/platform/scorecheck"
 )
 
-// registrar holds the registration interface
-var registrar interfaces.RegistrationInterface
-
-// SetRegistrar sets the registration interface
-func SetRegistrar(r interfaces.RegistrationInterface) {
-	registrar = r
-	// Register all handlers when registrar is set
-	registerHandlers()
+// init registers the memory game with the platform
+func init() {
+	message.RegisterGame(core.GameDefinition{
+		Type:         GameType,
+		StartMessage: "memory-startgame",
+		StartPayload: StartPayload{},
+		Messages: map[string]interface{}{
+			"memory-scoreupdate": ScoreUpdatePayload{},
+			"memory-flip":        FlipPayload{},
+		},
+		Factory: NewGame,
+	})
 }
 
-// registerHandlers registers all memory game message handlers
-func registerHandlers() {
-	if registrar == nil {
-		return
-	}
-	// Register memory game specific message types
-	registrar.RegisterHandler("memory-scoreupdate", HandleMemoryGameMessage)
-	// Register memory game start handler with specific message type
-	registrar.RegisterHandler("memory-startgame", HandleMemoryHostStartGame)
-}
-
-// HandleMemoryGameMessage processes memory game specific messages
-func HandleMemoryGameMessage(gameRoom *core.Room, client *core.Client, message core.Message) {
+// HandleMessage processes memory game specific messages
+func (g *Game) HandleMessage(client *core.Client, message core.Message) error {
 	switch message.Type {
 	case "memory-scoreupdate":
-		handleScoreUpdate(gameRoom, client, message)
+		return g.handleScoreUpdate(client, message)
+	case "memory-flip":
+		return g.handleFlip(client, message)
 	default:
-		log.Printf("[MEMORY] Unknown memory game message type: %s", message.Type)
-	}
-}
-
-// HandleMemoryHostStartGame handles memory game start messages
-func HandleMemoryHostStartGame(gameRoom *core.Room, client *core.Client, message core.Message) {
-	// Extract message data
-	dataMap, ok := message.Data.(map[string]interface{})
-	if !ok {
-		log.Printf("[MEMORY] Invalid message data format")
-		return
+		return core.NewError(core.ErrCodeUnknownMessageType, "unknown memory game message type: %s", message.Type)
 	}
-
-	log.Printf("[MEMORY] Starting memory game for host %s", client.Nickname)
-	// Call the existing memory game start handler
-	HandleHostStartGame(gameRoom, client, dataMap)
 }
 
 // handleScoreUpdate processes score update messages from client
-func handleScoreUpdate(gameRoom *core.Room, client *core.Client, message core.Message) {
+func (g *Game) handleScoreUpdate(client *core.Client, message core.Message) error {
+	gameRoom := g.room
 	log.Printf("[MEMORY] Processing scoreUpdate from %s", client.Nickname)
 
-	// Extract score data from message data
-	dataMap, ok := message.Data.(map[string]interface{})
-	if !ok {
-		log.Printf("[MEMORY] Invalid message data format")
-		return
+	payload := message.Data.(*ScoreUpdatePayload)
+	// Only players in the room keep a score
+	if !gameRoom.PlayerClients[client] {
+		return core.NewError(core.ErrCodeNotPlayer, "only players keep a score")
 	}
 
-	// Extract score from message
-	score, scoreOk := dataMap["score"].(float64)
-	if !scoreOk {
-		// Try to get score from nested data field
-		if nestedData, exists := dataMap["data"].(map[string]interface{}); exists {
-			if s, found := nestedData["score"].(float64); found {
-				score = s
-				scoreOk = true
-			}
-		}
+	// Authoritative games score matches on the server
+	if g.settings.Authoritative {
+		return core.NewError(core.ErrCodeInvalidMessage, "scores are kept by the server in this game; send memory-flip instead")
 	}
 
-	if !scoreOk {
-		log.Printf("[MEMORY] Invalid score in message data. Full message: %+v", dataMap)
-		return
+	// Implausible scores are refused or cut down and reported to the host
+	score, err := scorecheck.Check(gameRoom, GameType, g.scoreValidator(), client, scorecheck.Submission{
+		Previous: client.Score,
+		Reported: *payload.Score,
+		Elapsed:  g.room.PlayTime(g.startedAt),
+		Ended:    g.ended,
+	})
+	if err != nil {
+		return err
 	}
 
-	log.Printf("[MEMORY] Extracted score: %.0f from data: %+v", score, dataMap)
+	client.Score = score
+	gameRoom.LogScore(client)
+	log.Printf("[MEMORY] Updated score for %s: %d", client.Nickname, client.Score)
+
+	// Send updated leaderboard to host
+	sendPlayerLeaderboardToHost(gameRoom)
+	return nil
+}
 
-	// Update player score (only for non-host players)
-	if !client.IsHost {
-		client.Score = int(score)
-		log.Printf("[MEMORY] Updated score for %s: %d", client.Nickname, client.Score)
+// handleFlip processes a card flip in an authoritative game
+func (g *Game) handleFlip(client *core.Client, message core.Message) error {
+	payload := message.Data.(*FlipPayload)
 
-		// Send updated leaderboard to host
-		sendPlayerLeaderboardToHost(gameRoom)
-	} else {
-		log.Printf("[MEMORY] Ignoring score update for host player %s", client.Nickname)
+	if g.ended {
+		return core.NewError(core.ErrCodeGameEnded, "the memory game has already ended")
+	}
+	if !g.settings.Authoritative {
+		return core.NewError(core.ErrCodeInvalidMessage, "cards are dealt by the clients in this game; send memory-scoreupdate instead")
+	}
+	if !g.room.PlayerClients[client] {
+		return core.NewError(core.ErrCodeNotPlayer, "only players can flip cards")
+	}
+	if client.GameFinished {
+		return core.NewError(core.ErrCodeInvalidMessage, "your board is already cleared")
 	}
+
+	return g.flipCard(client, *payload.PositionID)
 }
 
 // sendPlayerLeaderboardToHost sends the current player leaderboard to the host
+// and the team standings to the players
 func sendPlayerLeaderboardToHost(gameRoom *core.Room) {
+	room.BroadcastTeamStandingsToPlayers(gameRoom, GameType)
 	if gameRoom.HostClient == nil {
 		log.Printf("[MEMORY] No host found in room %s", gameRoom.ID)
 		return
@@ -105,61 +103,73 @@ func sendPlayerLeaderboardToHost(gameRoom *core.Room) {
 
 	// Create leaderboard data
 	type PlayerScore struct {
+		PlayerID string `json:"playerId"`
 		Nickname string `json:"nickname"`
+		Team     string `json:"team,omitempty"`
 		Score    int    `json:"score"`
 		IsHost   bool   `json:"isHost"`
 	}
 
 	var leaderboard []PlayerScore
 	for client := range gameRoom.AllClients {
+		if client.IsSpectator {
+			continue
+		}
 		leaderboard = append(leaderboard, PlayerScore{
+			PlayerID: client.ID,
 			Nickname: client.Nickname,
+			Team:     client.Team,
 			Score:    client.Score,
 			IsHost:   client.IsHost,
 		})
 	}
 
-	// Send leaderboard to host
+	// Send leaderboard to host and spectators
 	leaderboardMessage := map[string]interface{}{
 		"type":        "memory-leaderboard",
 		"leaderboard": leaderboard,
 		"roomId":      gameRoom.ID,
 	}
 
-	room.BroadcastToHost(gameRoom, leaderboardMessage)
+	room.BroadcastToHostAndSpectators(gameRoom, room.WithTeamStandings(gameRoom, leaderboardMessage))
 	log.Printf("[MEMORY] Sent leaderboard to host %s in room %s", gameRoom.HostClient.Nickname, gameRoom.ID)
 }
 
-// HandleHostStartGame starts a memory game when the host initiates it
-func HandleHostStartGame(gameRoom *core.Room, client *core.Client, msgData map[string]interface{}) {
-	if !client.IsHost {
-		log.Printf("[MEMORY] Non-host %s tried to start game in room %s", client.Nickname, gameRoom.ID)
-		return
-	}
-
-	if gameRoom.GameStarted {
-		log.Printf("[MEMORY] Game already started in room %s", gameRoom.ID)
-		return
-	}
+// Start starts a memory game with the settings from the host's start message
+func (g *Game) Start(message core.Message) error {
+	gameRoom := g.room
+	payload := message.Data.(*StartPayload)
 
 	if gameRoom.TotalPlayers < 1 {
-		log.Printf("[MEMORY] Not enough players to start game in room %s", gameRoom.ID)
-		return
+		return core.NewError(core.ErrCodeNotEnoughPlayers, "not enough players to start game in room %s", gameRoom.ID)
 	}
 
 	// Extract game settings from message
 	numPairs := 8  // default value
 	gameTime := 60 // default value
 
-	if pairs, ok := msgData["numPairs"].(float64); ok {
-		numPairs = int(pairs)
+	if payload.NumPairs > 0 {
+		numPairs = payload.NumPairs
+	}
+	if payload.GameTime > 0 {
+		gameTime = payload.GameTime
 	}
-	if time, ok := msgData["gameTime"].(float64); ok {
-		gameTime = int(time)
+
+	// Every game draws its own seed from the room unless the host replays one
+	seed := payload.Seed
+	if seed == 0 {
+		seed = gameRoom.Rand.Int63()
 	}
 
-	log.Printf("[MEMORY] Starting game with %d pairs and %d seconds", numPairs, gameTime)
+	log.Printf("[MEMORY] Starting game with %d pairs and %d seconds (seed: %d)", numPairs, gameTime, seed)
 
 	// Start the memory game with settings
-	StartMemoryGame(gameRoom, numPairs, gameTime)
+	g.startMemoryGame(GameSettings{
+		NumPairs:      numPairs,
+		GameTime:      gameTime,
+		Authoritative: payload.Authoritative,
+		SharedDeck:    payload.SharedDeck,
+		Seed:          seed,
+	})
+	return nil
 }
@@ -2,18 +2,24 @@
 package memory
 
 import (
-	"encoding/json"
+	"hash/fnv"
 	"log"
 	"math/rand"
 	"sort"
 	"time"
 
 	"gaming-platform/core"
+	"gaming-platform/platform/results"
 	"gaming-platform/platform/room"
+	"gaming-platform/platform/scorecheck"
+	"gaming-platform/platform/store"
 )
 
-// GenerateCards creates a shuffled deck of memory cards with specified number of pairs
-func GenerateCards(numPairs int) []Card {
+// pointsPerPair is the score for each matched pair
+const pointsPerPair = 10
+
+// GenerateCards creates a deck of memory cards with the specified number of pairs, shuffled by rng
+func GenerateCards(numPairs int, rng *rand.Rand) []Card {
 	cards := []Card{}
 
 	// Define suits and values
@@ -52,8 +58,7 @@ func GenerateCards(numPairs int) []Card {
 	}
 
 	// Shuffle the cards
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(cards), func(i, j int) {
+	rng.Shuffle(len(cards), func(i, j int) {
 		cards[i], cards[j] = cards[j], cards[i]
 	})
 
@@ -66,27 +71,28 @@ func GenerateCards(numPairs int) []Card {
 	return cards
 }
 
-// HandleGameEnd processes the end of a memory game
-func HandleGameEnd(gameRoom *core.Room) {
+// handleGameEnd processes the end of a memory game
+func (g *Game) handleGameEnd() {
+	gameRoom := g.room
 	log.Printf("[MEMORY] Game ended for room %s", gameRoom.ID)
 
+	g.ended = true
 	gameRoom.GameEnded = true
 	gameRoom.WaitingForPlayers = false
 
-	// Stop the game timer
-	if gameRoom.Timer != nil {
-		gameRoom.Timer.Stop()
-	}
-
 	// Calculate final scores and rankings
-	playerScores := CalculateScores(gameRoom)
+	playerScores := g.scores()
+
+	// Record the game before announcing it so clients can look it up
+	resultID := results.Record(gameRoom, GameType, g.settings, g.settings.Seed, g.startedAt, playerResults(playerScores))
 
 	// Broadcast game end to all clients
-	room.BroadcastToRoom(gameRoom, map[string]interface{}{
-		"type":    "memory-gameended",
-		"scores":  playerScores,
-		"message": "Memory game completed!",
-	})
+	room.BroadcastToRoom(gameRoom, room.WithTeamStandings(gameRoom, map[string]interface{}{
+		"type":     "memory-gameended",
+		"scores":   playerScores,
+		"message":  "Memory game completed!",
+		"resultId": resultID,
+	}))
 
 	log.Printf("[MEMORY] Final scores for room %s: %+v", gameRoom.ID, playerScores)
 }
@@ -98,7 +104,9 @@ func CalculateScores(gameRoom *core.Room) []PlayerScore {
 	// Collect player scores from PlayerClients
 	for client := range gameRoom.PlayerClients {
 		playerScores = append(playerScores, PlayerScore{
+			PlayerID: client.ID,
 			Nickname: client.Nickname,
+			Team:     client.Team,
 			Score:    client.Score,
 		})
 	}
@@ -116,75 +124,59 @@ func CalculateScores(gameRoom *core.Room) []PlayerScore {
 	return playerScores
 }
 
-// StartMemoryGame initializes and starts a memory game
-func StartMemoryGame(gameRoom *core.Room, numPairs int, gameTime int) {
-	log.Printf("[MEMORY] Starting memory game for room %s with %d pairs", gameRoom.ID, numPairs)
+// startMemoryGame initializes and starts a memory game
+func (g *Game) startMemoryGame(settings GameSettings) {
+	gameRoom := g.room
+	numPairs := settings.NumPairs
+	gameTime := settings.GameTime
+	log.Printf("[MEMORY] Starting memory game for room %s with %d pairs (authoritative: %t)", gameRoom.ID, numPairs, settings.Authoritative)
 
 	// Initialize game data without cards (cards will be generated on client side)
-	gameData := GameData{
+	g.data = GameData{
 		Cards:        []Card{}, // Empty cards array
 		GameTime:     gameTime,
 		FlippedCards: []CardRef{},
 	}
+	g.settings = settings
+	g.rng = rand.New(rand.NewSource(settings.Seed))
 
-	// Store game data in room
-	gameDataBytes, err := json.Marshal(gameData)
-	if err != nil {
-		log.Printf("Error marshaling game data: %v", err)
-		return
+	// In authoritative games the server deals every player a deck, all from
+	// the same shuffle when the deck is shared
+	g.boards = make(map[string]*Board)
+	if settings.Authoritative && settings.SharedDeck {
+		g.data.Cards = GenerateCards(numPairs, g.rng)
 	}
 
+	g.startedAt = time.Now()
+
 	// Set game state
-	gameRoom.GameData = gameDataBytes
 	gameRoom.GameStarted = true
 	gameRoom.GameEnded = false
 	gameRoom.WaitingForPlayers = false
-	gameRoom.GameTime = gameData.GameTime
+	gameRoom.GameTime = g.data.GameTime
 
 	// Reset player scores
 	for client := range gameRoom.PlayerClients {
 		client.Score = 0
+		client.GameFinished = false
+		if settings.Authoritative {
+			g.board(client)
+		}
 	}
 
-	// Start game timer (countdown from gameTime to 0)
-	gameRoom.Timer = time.NewTicker(1 * time.Second)
-	go func() {
-		for {
-			select {
-			case <-gameRoom.Timer.C:
-				gameRoom.GameTime--
-				// Broadcast game time update every second
-				room.BroadcastToRoom(gameRoom, map[string]interface{}{
-					"type":     "memory-timeupdate",
-					"timeLeft": gameRoom.GameTime,
-				})
-
-				// Check if time is up
-				if gameRoom.GameTime <= 0 {
-					log.Printf("[MEMORY] Time up for room %s, ending game", gameRoom.ID)
-					HandleGameEnd(gameRoom)
-					return
-				}
-			case <-gameRoom.StopChan:
-				gameRoom.Timer.Stop()
-				return
-			}
-		}
-	}()
+	// Start game timer (countdown from gameTime to 0) on the room's event loop
+	g.stopTimer = gameRoom.Every(1*time.Second, g.tick)
 
 	// Create client game data with only game settings (no cards)
-	clientGameData := map[string]interface{}{
-		"gameSettings": GameSettings{
-			NumPairs: numPairs,
-			GameTime: gameTime,
-		},
-		"gameTime": gameTime,
+	clientGameData := ClientGameData{
+		GameSettings: g.settings,
+		GameTime:     gameTime,
 	}
 
 	// Broadcast game start to all clients with game parameters only
 	room.BroadcastToRoom(gameRoom, map[string]interface{}{
 		"type":     "platformGameStarted",
-		"gameType": "memory",
+		"gameType": GameType,
 		"gameData": clientGameData,
 		"message":  "Memory game started!",
 	})
@@ -192,4 +184,170 @@ func StartMemoryGame(gameRoom *core.Room, numPairs int, gameTime int) {
 	log.Printf("[MEMORY] Memory game started for room %s with %d pairs and %d seconds", gameRoom.ID, numPairs, gameTime)
 }
 
-// HandleTwoCardsClick processes two cards being clicked simultaneously
+// tick counts the game time down and ends the game when it reaches zero
+func (g *Game) tick() {
+	g.room.GameTime--
+	g.room.LogTick(g.room.GameTime)
+	// Broadcast game time update every second
+	room.BroadcastToRoom(g.room, map[string]interface{}{
+		"type":     "memory-timeupdate",
+		"timeLeft": g.room.GameTime,
+	})
+
+	// Check if time is up
+	if g.room.GameTime <= 0 {
+		log.Printf("[MEMORY] Time up for room %s, ending game", g.room.ID)
+		g.End()
+	}
+}
+
+// playerResults converts the final leaderboard into recorded player results
+func playerResults(scores []PlayerScore) []store.PlayerResult {
+	players := make([]store.PlayerResult, 0, len(scores))
+	for _, score := range scores {
+		players = append(players, store.PlayerResult{
+			PlayerID:  score.PlayerID,
+			Nickname:  score.Nickname,
+			Rank:      score.Rank,
+			Score:     score.Score,
+			Collected: score.PairsFound,
+		})
+	}
+	return players
+}
+
+// scores ranks the players and adds the pairs they found. Clients that deal
+// their own cards report only a score, so their pairs follow from it.
+func (g *Game) scores() []PlayerScore {
+	playerScores := CalculateScores(g.room)
+	for i := range playerScores {
+		if board, exists := g.boards[playerScores[i].PlayerID]; exists {
+			playerScores[i].PairsFound = board.PairsFound
+		} else if !g.settings.Authoritative {
+			playerScores[i].PairsFound = playerScores[i].Score / pointsPerPair
+		}
+	}
+	return playerScores
+}
+
+// board returns the player's authoritative deck, dealing one on first use
+func (g *Game) board(client *core.Client) *Board {
+	board, exists := g.boards[client.ID]
+	if exists {
+		return board
+	}
+
+	board = &Board{}
+	if g.settings.SharedDeck {
+		board.Cards = append([]Card(nil), g.data.Cards...)
+	} else {
+		board.Cards = GenerateCards(g.settings.NumPairs, g.playerRand(client.ID))
+	}
+	g.boards[client.ID] = board
+	return board
+}
+
+// playerRand returns a random source for a player's own deck. It is derived
+// from the game seed and the player ID, so a player resuming the same seat in
+// a replay gets the same deck whatever order the boards are dealt in.
+func (g *Game) playerRand(playerID string) *rand.Rand {
+	hash := fnv.New64a()
+	hash.Write([]byte(playerID))
+	return rand.New(rand.NewSource(g.settings.Seed ^ int64(hash.Sum64())))
+}
+
+// flipCard turns over a card on the player's board and settles the pair once
+// two cards are face up. Matched pairs score pointsPerPair.
+func (g *Game) flipCard(client *core.Client, positionID int) error {
+	board := g.board(client)
+	if positionID >= len(board.Cards) {
+		return core.NewError(core.ErrCodeInvalidPayload, "there is no card at position %d", positionID)
+	}
+	card := &board.Cards[positionID]
+	if card.IsFlipped || card.IsMatched {
+		return core.NewError(core.ErrCodeInvalidPayload, "the card at position %d is already face up", positionID)
+	}
+
+	card.IsFlipped = true
+	flipped := CardRef{Suit: card.Suit, Value: card.Value, PositionId: card.PositionId}
+	room.SendToClient(client, map[string]interface{}{
+		"type": "memory-cardflipped",
+		"card": flipped,
+	})
+
+	// Wait for the second card of the pair
+	if len(board.FlippedCards) == 0 {
+		board.FlippedCards = append(board.FlippedCards, flipped)
+		return nil
+	}
+	first := board.FlippedCards[0]
+	board.FlippedCards = board.FlippedCards[:0]
+	firstCard := &board.Cards[first.PositionId]
+	positions := []int{first.PositionId, flipped.PositionId}
+
+	if first.Suit != flipped.Suit || first.Value != flipped.Value {
+		firstCard.IsFlipped = false
+		card.IsFlipped = false
+		room.SendToClient(client, map[string]interface{}{
+			"type":        "memory-mismatch",
+			"positionIds": positions,
+		})
+		return nil
+	}
+
+	firstCard.IsMatched = true
+	card.IsMatched = true
+	board.PairsFound++
+	client.Score += pointsPerPair
+	g.room.LogScore(client)
+	log.Printf("[MEMORY] %s matched a pair in room %s (%d/%d)", client.Nickname, g.room.ID, board.PairsFound, g.settings.NumPairs)
+
+	room.SendToClient(client, map[string]interface{}{
+		"type":        "memory-match",
+		"positionIds": positions,
+		"score":       client.Score,
+		"pairsFound":  board.PairsFound,
+	})
+	sendPlayerLeaderboardToHost(g.room)
+
+	if board.PairsFound == g.settings.NumPairs {
+		client.GameFinished = true
+		g.endIfAllFinished()
+	}
+	return nil
+}
+
+// endIfAllFinished ends the game early once every player has cleared their board
+func (g *Game) endIfAllFinished() {
+	for client := range g.room.PlayerClients {
+		if !client.GameFinished {
+			return
+		}
+	}
+	log.Printf("[MEMORY] Every player cleared their board in room %s, ending game", g.room.ID)
+	g.End()
+}
+
+// view returns the matched cards of a board, which may be shown to anyone
+func (b *Board) view() BoardView {
+	view := BoardView{
+		Matched:    []CardRef{},
+		PairsFound: b.PairsFound,
+	}
+	for _, card := range b.Cards {
+		if card.IsMatched {
+			view.Matched = append(view.Matched, CardRef{Suit: card.Suit, Value: card.Value, PositionId: card.PositionId})
+		}
+	}
+	return view
+}
+
+// scoreValidator bounds the scores clients report: no more than every pair
+// on the board, at a pace of about one pair a second
+func (g *Game) scoreValidator() scorecheck.Validator {
+	return scorecheck.Limits{
+		MaxScore:           g.settings.NumPairs * pointsPerPair,
+		MaxPointsPerSecond: pointsPerPair,
+		Burst:              2 * pointsPerPair,
+	}
+}
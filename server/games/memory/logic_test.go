@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateCardsIsDeterministicForASeed(t *testing.T) {
+	first := GenerateCards(8, rand.New(rand.NewSource(42)))
+	second := GenerateCards(8, rand.New(rand.NewSource(42)))
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("decks for the same seed differ:\n%v\n%v", first, second)
+	}
+
+	other := GenerateCards(8, rand.New(rand.NewSource(43)))
+	if reflect.DeepEqual(first, other) {
+		t.Fatal("decks for different seeds are identical")
+	}
+}
+
+func TestGenerateCardsDealsPairs(t *testing.T) {
+	cards := GenerateCards(8, rand.New(rand.NewSource(1)))
+	if len(cards) != 16 {
+		t.Fatalf("%d cards, want 16", len(cards))
+	}
+
+	counts := make(map[string]int)
+	for i, card := range cards {
+		if card.PositionId != i {
+			t.Errorf("card %d has position %d", i, card.PositionId)
+		}
+		if card.IsFlipped || card.IsMatched {
+			t.Errorf("card %d starts flipped or matched", i)
+		}
+		counts[card.Suit+card.Value]++
+	}
+	for card, count := range counts {
+		if count != 2 {
+			t.Errorf("%s dealt %d times, want 2", card, count)
+		}
+	}
+
+	if cards := GenerateCards(100, rand.New(rand.NewSource(1))); len(cards) != 104 {
+		t.Errorf("%d cards for too many pairs, want the full 104", len(cards))
+	}
+}
+
+func TestPlayerDecksDependOnSeedAndPlayer(t *testing.T) {
+	game := &Game{settings: GameSettings{Seed: 7}}
+	deck := func(playerID string) []Card {
+		return GenerateCards(8, game.playerRand(playerID))
+	}
+
+	if !reflect.DeepEqual(deck("p_1"), deck("p_1")) {
+		t.Error("a player's deck changed for the same seed")
+	}
+	if reflect.DeepEqual(deck("p_1"), deck("p_2")) {
+		t.Error("two players got the same deck")
+	}
+}
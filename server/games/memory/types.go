@@ -26,8 +26,24 @@ type CardRef struct {
 
 // GameSettings represents game configuration sent to clients
 type GameSettings struct {
-	NumPairs int `json:"numPairs"`
-	GameTime int `json:"gameTime"`
+	NumPairs      int   `json:"numPairs"`
+	GameTime      int   `json:"gameTime"`
+	Authoritative bool  `json:"authoritative,omitempty"` // The server deals the cards and keeps the score
+	SharedDeck    bool  `json:"sharedDeck,omitempty"`    // Every player gets the same card layout
+	Seed          int64 `json:"-"`                       // Seeds the shuffles; kept from clients, who could rebuild every deck from it
+}
+
+// Board is a player's own deck in an authoritative game
+type Board struct {
+	Cards        []Card    // Faces stay on the server until flipped
+	FlippedCards []CardRef // Face-up card waiting for its match
+	PairsFound   int
+}
+
+// BoardView is the part of a player's board that may be shown to clients
+type BoardView struct {
+	Matched    []CardRef `json:"matched"`
+	PairsFound int       `json:"pairsFound"`
 }
 
 // ClientGameData represents the game data sent to clients (without full card details)
@@ -38,7 +54,42 @@ type ClientGameData struct {
 
 // PlayerScore represents a player's score for ranking
 type PlayerScore struct {
-	Nickname string `json:"nickname"`
-	Score    int    `json:"score"`
-	Rank     int    `json:"rank"`
+	PlayerID   string `json:"playerId"`
+	Nickname   string `json:"nickname"`
+	Team       string `json:"team,omitempty"`
+	Score      int    `json:"score"`
+	Rank       int    `json:"rank"`
+	PairsFound int    `json:"pairsFound,omitempty"`
+}
+
+// Snapshot represents the memory game state returned by Game.Snapshot
+type Snapshot struct {
+	GameType string        `json:"gameType"`
+	Settings GameSettings  `json:"gameSettings"`
+	TimeLeft int           `json:"timeLeft"`
+	Ended    bool          `json:"ended"`
+	Scores   []PlayerScore `json:"scores"`
+	// Matched cards per player ID in authoritative games
+	Boards map[string]BoardView `json:"boards,omitempty"`
+	// The resuming player's own face-up card waiting for its pair
+	Flipped []CardRef `json:"flipped,omitempty"`
+}
+
+// StartPayload represents the settings sent by the host in memory-startgame
+type StartPayload struct {
+	NumPairs      int   `json:"numPairs" validate:"omitempty,min=1,max=26"`
+	GameTime      int   `json:"gameTime" validate:"omitempty,min=1,max=3600"`
+	Authoritative bool  `json:"authoritative"`
+	SharedDeck    bool  `json:"sharedDeck"`
+	Seed          int64 `json:"seed"` // Replays a recorded game; zero draws a new seed
+}
+
+// FlipPayload represents a memory-flip message turning over one card in an authoritative game
+type FlipPayload struct {
+	PositionID *int `json:"positionId" validate:"required,min=0"`
+}
+
+// ScoreUpdatePayload represents a memory-scoreupdate message from a player
+type ScoreUpdatePayload struct {
+	Score *int `json:"score" validate:"required,min=0"`
 }
@@ -0,0 +1,110 @@
+package redenvelope
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"gaming-platform/core"
+	"gaming-platform/platform/room"
+)
+
+// GameType is the registered type name of the red envelope game
+const GameType = "redenvelope"
+
+// Game is a red envelope game instance owned by a room
+type Game struct {
+	room      *core.Room
+	data      *GameData
+	settings  GameSettings
+	ended     bool
+	startedAt time.Time
+	stopTimer func()
+
+	// Authoritative mode state
+	rng            *rand.Rand // Seeded with settings.Seed
+	stopSpawner    func()
+	nextEnvelopeID int
+
+	collected map[string]int // Envelopes collected per player ID
+}
+
+// NewGame creates a red envelope game bound to the given room
+func NewGame(gameRoom *core.Room) core.Game {
+	return &Game{
+		room: gameRoom,
+		data: &GameData{},
+	}
+}
+
+// Type returns the game type
+func (g *Game) Type() string {
+	return GameType
+}
+
+// OnPlayerJoin sends the running game settings to a late joining player
+func (g *Game) OnPlayerJoin(client *core.Client) {
+	if !g.data.Active || client.IsHost {
+		return
+	}
+
+	room.SendToClient(client, map[string]interface{}{
+		"type":     "platformGameStarted",
+		"gameType": GameType,
+		"data": map[string]interface{}{
+			"gameData": g.data,
+			"settings": g.settings,
+		},
+	})
+}
+
+// OnPlayerLeave refreshes the leaderboard after a player leaves
+func (g *Game) OnPlayerLeave(client *core.Client) {
+	if g.ended || client.IsHost || client.IsSpectator {
+		return
+	}
+	room.BroadcastToHostAndSpectators(g.room, room.WithTeamStandings(g.room, map[string]interface{}{
+		"type":        "redenvelope-leaderboard",
+		"leaderboard": g.leaderboard(),
+	}))
+	room.BroadcastTeamStandingsToPlayers(g.room, GameType)
+}
+
+// Snapshot returns the current red envelope game state
+func (g *Game) Snapshot() interface{} {
+	return Snapshot{
+		GameType: GameType,
+		Settings: g.settings,
+		GameData: *g.data,
+		Players:  g.leaderboard(),
+	}
+}
+
+// OnResume pushes the expiry of the envelopes on screen back by the pause, so
+// they stay up as long as they would have without it
+func (g *Game) OnResume(paused time.Duration) {
+	for i := range g.data.Envelopes {
+		g.data.Envelopes[i].SpawnTime = g.data.Envelopes[i].SpawnTime.Add(paused)
+		g.data.Envelopes[i].ExpiresAt = g.data.Envelopes[i].ExpiresAt.Add(paused)
+	}
+}
+
+// End stops the game timer and broadcasts the final scores
+func (g *Game) End() {
+	if g.ended {
+		return
+	}
+	g.stop()
+	g.handleGameEnd()
+}
+
+// stop stops the game timer and the envelope spawner
+func (g *Game) stop() {
+	if g.stopTimer != nil {
+		g.stopTimer()
+		log.Printf("[REDENVELOPE] Timer stopped for room %s", g.room.ID)
+	}
+	if g.stopSpawner != nil {
+		g.stopSpawner()
+	}
+}
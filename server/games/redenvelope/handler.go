@@ -4,135 +4,127 @@ import (
 	"log"
 
 	"gaming-platform/core"
-	"gaming-platform/core/interfaces"
+	"gaming-platform/core/message"
 	"gaming-platform/platform/room"
+	"gaming-platform/platform/scorecheck"
 )
 
-// registrar holds the registration interface
-var registrar interfaces.RegistrationInterface
-
-// SetRegistrar sets the registration interface
-func SetRegistrar(r interfaces.RegistrationInterface) {
-	registrar = r
-	// Register all handlers when registrar is set
-	registerHandlers()
-}
-
-// registerHandlers registers all red envelope game message handlers
-func registerHandlers() {
-	if registrar == nil {
-		return
-	}
-	// Register red envelope game specific message types
-	registrar.RegisterHandler("redenvelope-scoreupdate", HandleRedEnvelopeGameMessage)
-	// Register red envelope game start handler
-	registrar.RegisterHandler("redenvelope-startgame", HandleRedEnvelopeHostStartGame)
+// init registers the red envelope game with the platform
+func init() {
+	message.RegisterGame(core.GameDefinition{
+		Type:         GameType,
+		StartMessage: "redenvelope-startgame",
+		StartPayload: StartPayload{},
+		Messages: map[string]interface{}{
+			"redenvelope-scoreupdate": ScoreUpdatePayload{},
+			"redenvelope-grab":        GrabPayload{},
+		},
+		Factory: NewGame,
+	})
 }
 
-// HandleRedEnvelopeGameMessage processes red envelope game specific messages
-func HandleRedEnvelopeGameMessage(gameRoom *core.Room, client *core.Client, message core.Message) {
+// HandleMessage processes red envelope game specific messages
+func (g *Game) HandleMessage(client *core.Client, message core.Message) error {
 	switch message.Type {
 	case "redenvelope-scoreupdate":
-		handleScoreUpdate(gameRoom, client, message)
+		return g.handleScoreUpdate(client, message)
+	case "redenvelope-grab":
+		return g.handleGrab(client, message)
 	default:
-		log.Printf("[REDENVELOPE] Unknown red envelope game message type: %s", message.Type)
+		return core.NewError(core.ErrCodeUnknownMessageType, "unknown red envelope game message type: %s", message.Type)
 	}
 }
 
-// HandleRedEnvelopeHostStartGame handles red envelope game start messages
-func HandleRedEnvelopeHostStartGame(gameRoom *core.Room, client *core.Client, message core.Message) {
-	log.Printf("[REDENVELOPE] Starting red envelope game for host %s", client.Nickname)
-	// Call the existing red envelope game start handler
-	handleHostStartGame(gameRoom, client, message)
-}
+// Start starts a red envelope game with the settings from the host's start message
+func (g *Game) Start(message core.Message) error {
+	log.Printf("[REDENVELOPE] Starting red envelope game in room %s", g.room.ID)
 
-// handleHostStartGame handles game start from host
-func handleHostStartGame(gameRoom *core.Room, client *core.Client, message core.Message) {
-	if !client.IsHost {
-		log.Printf("[REDENVELOPE] Non-host %s tried to start game", client.Nickname)
-		return
-	}
+	payload := message.Data.(*StartPayload)
 
-	log.Printf("[REDENVELOPE] Host %s starting game", client.Nickname)
-
-	// Extract game settings from message data
-	data, ok := message.Data.(map[string]interface{})
-	if !ok {
-		log.Printf("[REDENVELOPE] Invalid message data format")
-		return
-	}
-
-	// Helper function to safely extract float64 values with defaults
-	getFloat64 := func(key string, defaultValue float64) float64 {
-		if val, exists := data[key]; exists && val != nil {
-			if floatVal, ok := val.(float64); ok {
-				return floatVal
-			}
+	// Fall back to defaults for settings the host left out
+	withDefault := func(value, defaultValue int) int {
+		if value > 0 {
+			return value
 		}
 		return defaultValue
 	}
 
 	settings := GameSettings{
-		Duration:         int(getFloat64("duration", 60)),
-		SpawnInterval:    int(getFloat64("spawnInterval", 2)),
-		EnvelopeLifetime: int(getFloat64("envelopeLifetime", 5)),
-		EnvelopeCount:    int(getFloat64("envelopeCount", 10)),
+		Duration:         withDefault(payload.Duration, 60),
+		SpawnInterval:    withDefault(payload.SpawnInterval, 500),
+		EnvelopeLifetime: withDefault(payload.EnvelopeLifetime, 3000),
+		EnvelopeCount:    withDefault(payload.EnvelopeCount, 10),
+		Authoritative:    payload.Authoritative,
+		Seed:             payload.Seed,
+	}
+
+	// Every game draws its own seed from the room unless the host replays one
+	if settings.Seed == 0 {
+		settings.Seed = g.room.Rand.Int63()
 	}
 
 	// Start the game
-	StartRedEnvelopeGame(gameRoom, settings)
+	g.startRedEnvelopeGame(settings)
+	return nil
 }
 
 // handleScoreUpdate processes score update messages from client
-func handleScoreUpdate(gameRoom *core.Room, client *core.Client, message core.Message) {
+func (g *Game) handleScoreUpdate(client *core.Client, message core.Message) error {
 	log.Printf("[REDENVELOPE] Processing scoreUpdate from %s", client.Nickname)
 
-	// Debug: Print the entire message structure
-	log.Printf("[REDENVELOPE] Full message: %+v", message)
-	log.Printf("[REDENVELOPE] Message.Data type: %T", message.Data)
-	log.Printf("[REDENVELOPE] Message.Data value: %+v", message.Data)
+	payload := message.Data.(*ScoreUpdatePayload)
 
-	// Extract score data from message
-	dataMap, ok := message.Data.(map[string]interface{})
-	if !ok {
-		log.Printf("[REDENVELOPE] Invalid message data format")
-		return
+	// Authoritative games settle grabs on the server
+	if g.settings.Authoritative {
+		return core.NewError(core.ErrCodeInvalidMessage, "scores are kept by the server in this game; send redenvelope-grab instead")
 	}
-
-	// Debug: Print the dataMap contents
-	log.Printf("[REDENVELOPE] DataMap contents: %+v", dataMap)
-	for key, value := range dataMap {
-		log.Printf("[REDENVELOPE] Key: %s, Value: %+v, Type: %T", key, value, value)
+	if !g.room.PlayerClients[client] {
+		return core.NewError(core.ErrCodeNotPlayer, "only players keep a score")
 	}
 
-	// Extract data from dataMap['data']
-	rawData, ok := dataMap["data"]
-	if !ok {
-		log.Printf("[REDENVELOPE] 'data' key not found in message data")
-		return
-	}
-	// extract totalScore from rawData
-	rawScore, ok := rawData.(map[string]interface{})
-	if !ok {
-		log.Printf("[REDENVELOPE] 'data' is not a map")
-		return
-	}
-	totalScore, ok := rawScore["totalScore"].(float64)
-	if !ok {
-		log.Printf("[REDENVELOPE] 'totalScore' key not found in message data")
-		return
+	// Implausible scores are refused or cut down and reported to the host
+	elapsed := g.room.PlayTime(g.startedAt)
+	totalScore, err := scorecheck.Check(g.room, GameType, g.scoreValidator(), client, scorecheck.Submission{
+		Previous: client.Score,
+		Reported: *payload.TotalScore,
+		Elapsed:  elapsed,
+		Ended:    g.ended,
+	})
+	if err != nil {
+		return err
 	}
 
 	// Update player score in game
-	UpdatePlayerScore(gameRoom, client.Nickname, client.Nickname, int(totalScore))
+	if err := g.updatePlayerScore(client, totalScore); err != nil {
+		return err
+	}
+	g.collected[client.ID] = g.plausibleCollected(payload.CollectedCount, totalScore, elapsed)
 
-	// Calculate and send updated leaderboard to host
-	leaderboard := calculateLeaderboard(gameRoom)
+	// Calculate and send updated leaderboard to host and spectators
+	leaderboard := g.leaderboard()
 
-	room.BroadcastToHost(gameRoom, map[string]interface{}{
+	room.BroadcastToHostAndSpectators(g.room, room.WithTeamStandings(g.room, map[string]interface{}{
 		"type":        "redenvelope-leaderboard",
 		"leaderboard": leaderboard,
-	})
+	}))
+
+	log.Printf("[REDENVELOPE] Player %s updated total score to %d", client.Nickname, totalScore)
+	return nil
+}
+
+// handleGrab processes an envelope grab in an authoritative game
+func (g *Game) handleGrab(client *core.Client, message core.Message) error {
+	payload := message.Data.(*GrabPayload)
+
+	if g.ended {
+		return core.NewError(core.ErrCodeGameEnded, "the game has already ended")
+	}
+	if !g.settings.Authoritative {
+		return core.NewError(core.ErrCodeInvalidMessage, "envelopes are dropped by the clients in this game; send redenvelope-scoreupdate instead")
+	}
+	if !g.room.PlayerClients[client] {
+		return core.NewError(core.ErrCodeNotPlayer, "only players can grab envelopes")
+	}
 
-	log.Printf("[REDENVELOPE] Player %s updated total score to %d", client.Nickname, int(totalScore))
+	return g.grabEnvelope(client, payload.EnvelopeID)
 }
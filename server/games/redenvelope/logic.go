@@ -1,71 +1,71 @@
 package redenvelope
 
 import (
+	"fmt"
 	"log"
+	"math/rand"
 	"sort"
 	"time"
 
 	"gaming-platform/core"
+	"gaming-platform/platform/results"
 	"gaming-platform/platform/room"
+	"gaming-platform/platform/scorecheck"
+	"gaming-platform/platform/store"
 )
 
-// startTimeUpdates handles the game timer and broadcasts time updates
-func startTimeUpdates(gameRoom *core.Room, gameData *GameData) {
-	ticker := time.NewTicker(1 * time.Second)
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				gameData.TimeLeft--
-
-				// Broadcast time update
-				room.BroadcastToRoom(gameRoom, map[string]interface{}{
-					"type": "redenvelope-timeupdate",
-					"data": map[string]interface{}{
-						"timeLeft": gameData.TimeLeft,
-					},
-				})
-
-				// Check if game should end
-				if gameData.TimeLeft <= 0 {
-					log.Printf("[REDENVELOPE] Time up for room %s, ending game", gameRoom.ID)
-					HandleGameEnd(gameRoom)
-					return
-				}
-			case <-gameRoom.StopChan:
-				return
-			}
-		}
-	}()
+// tick counts the game time down, broadcasts time updates and ends the game at zero
+func (g *Game) tick() {
+	g.data.TimeLeft--
+	g.room.GameTime = g.data.TimeLeft
+	g.room.LogTick(g.data.TimeLeft)
+
+	// Broadcast time update
+	room.BroadcastToRoom(g.room, map[string]interface{}{
+		"type": "redenvelope-timeupdate",
+		"data": map[string]interface{}{
+			"timeLeft": g.data.TimeLeft,
+		},
+	})
+
+	// Check if game should end
+	if g.data.TimeLeft <= 0 {
+		log.Printf("[REDENVELOPE] Time up for room %s, ending game", g.room.ID)
+		g.End()
+	}
 }
 
-// UpdatePlayerScore updates a player's total score
-func UpdatePlayerScore(gameRoom *core.Room, playerID, nickname string, totalScore int) {
-	if gameRoom.GameEnded {
-		return
+// updatePlayerScore updates a player's total score
+func (g *Game) updatePlayerScore(client *core.Client, totalScore int) error {
+	gameRoom := g.room
+	if g.ended {
+		return core.NewError(core.ErrCodeGameEnded, "the game has already ended")
 	}
-	// Find the client and update their score
-	for client := range gameRoom.PlayerClients {
-		if client.Nickname == nickname {
-			client.Score = totalScore
-			log.Printf("[REDENVELOPE] Player %s score updated to %d in room %s", nickname, totalScore, gameRoom.ID)
-			// Broadcast score update
-			//room.BroadcastToRoom(gameRoom, map[string]interface{}{
-			//	"type":   "redenvelope-scoreupdate",
-			//	"player": nickname,
-			//	"score":  totalScore,
-			//})
-			break
-		}
+	// Only players in the room keep a score
+	if _, isPlayer := gameRoom.PlayerClients[client]; !isPlayer {
+		return core.NewError(core.ErrCodeNotPlayer, "only players keep a score")
 	}
+	client.Score = totalScore
+	gameRoom.LogScore(client)
+	log.Printf("[REDENVELOPE] Player %s score updated to %d in room %s", client.Nickname, totalScore, gameRoom.ID)
+
 	// Broadcast leaderboard update
-	leaderboard := calculateLeaderboard(gameRoom)
-	room.BroadcastToHost(gameRoom, map[string]interface{}{
+	leaderboard := g.leaderboard()
+	room.BroadcastToHostAndSpectators(gameRoom, room.WithTeamStandings(gameRoom, map[string]interface{}{
 		"type":    "redenvelope-leaderboard",
 		"players": leaderboard,
-	})
+	}))
+	room.BroadcastTeamStandingsToPlayers(gameRoom, GameType)
+	return nil
+}
 
+// leaderboard ranks the players and adds the envelopes each one collected
+func (g *Game) leaderboard() []PlayerScore {
+	players := calculateLeaderboard(g.room)
+	for i := range players {
+		players[i].CollectedCount = g.collected[players[i].PlayerID]
+	}
+	return players
 }
 
 // calculateLeaderboard calculates and returns player rankings
@@ -75,10 +75,11 @@ func calculateLeaderboard(gameRoom *core.Room) []PlayerScore {
 	// Collect player scores from PlayerClients
 	for client := range gameRoom.PlayerClients {
 		players = append(players, PlayerScore{
-			Nickname:       client.Nickname,
-			Score:          client.Score,
-			CollectedCount: 0, // Not tracking collected count in simplified version
-			Rank:           0,
+			PlayerID: client.ID,
+			Nickname: client.Nickname,
+			Team:     client.Team,
+			Score:    client.Score,
+			Rank:     0,
 		})
 	}
 
@@ -95,65 +96,192 @@ func calculateLeaderboard(gameRoom *core.Room) []PlayerScore {
 	return players
 }
 
-// HandleGameEnd processes the end of a red envelope game
-func HandleGameEnd(gameRoom *core.Room) {
+// handleGameEnd processes the end of a red envelope game
+func (g *Game) handleGameEnd() {
+	gameRoom := g.room
 	log.Printf("[REDENVELOPE] Game ended for room %s", gameRoom.ID)
 
+	g.ended = true
+	g.data.Active = false
 	gameRoom.GameEnded = true
 	gameRoom.WaitingForPlayers = false
 
-	// Stop the game timer
-	if gameRoom.Timer != nil {
-		gameRoom.Timer.Stop()
-	}
-
 	// Calculate final scores and rankings
-	leaderboard := calculateLeaderboard(gameRoom)
+	leaderboard := g.leaderboard()
+
+	// Record the game before announcing it so clients can look it up
+	resultID := results.Record(gameRoom, GameType, g.settings, g.settings.Seed, g.startedAt, playerResults(leaderboard))
 
 	// Broadcast game end to all clients
-	room.BroadcastToRoom(gameRoom, map[string]interface{}{
-		"type":    "redenvelope-gameend",
-		"players": leaderboard,
-		"message": "Red envelope game completed!",
-	})
+	room.BroadcastToRoom(gameRoom, room.WithTeamStandings(gameRoom, map[string]interface{}{
+		"type":     "redenvelope-gameend",
+		"players":  leaderboard,
+		"message":  "Red envelope game completed!",
+		"resultId": resultID,
+	}))
 
 	log.Printf("[REDENVELOPE] Final scores for room %s: %+v", gameRoom.ID, leaderboard)
 }
 
-// StartRedEnvelopeGame initializes and starts a red envelope game
-func StartRedEnvelopeGame(gameRoom *core.Room, settings GameSettings) {
+// startRedEnvelopeGame initializes and starts a red envelope game
+func (g *Game) startRedEnvelopeGame(settings GameSettings) {
+	gameRoom := g.room
 	log.Printf("[REDENVELOPE] Starting red envelope game for room %s with settings: %+v", gameRoom.ID, settings)
 
 	// Initialize game data
-	gameData := &GameData{
+	g.settings = settings
+	g.data = &GameData{
 		TimeLeft: settings.Duration,
 		Active:   true,
 	}
 
-	// Store game data in room
-	gameRoom.GameData = gameData
+	g.startedAt = time.Now()
+
+	// Set game state
+	gameRoom.GameTime = settings.Duration
 	gameRoom.GameStarted = true
+	gameRoom.GameEnded = false
+	gameRoom.WaitingForPlayers = false
 
 	// Reset all client scores
 	for client := range gameRoom.AllClients {
 		client.Score = 0
 	}
+	g.rng = rand.New(rand.NewSource(settings.Seed))
+	g.collected = make(map[string]int)
+
+	// Start game timer on the room's event loop
+	g.stopTimer = gameRoom.Every(1*time.Second, g.tick)
 
-	// Start game timer
-	go startTimeUpdates(gameRoom, gameData)
+	// In authoritative games the server drops the envelopes
+	if settings.Authoritative {
+		g.stopSpawner = gameRoom.Every(time.Duration(settings.SpawnInterval)*time.Millisecond, g.spawnEnvelope)
+	}
 
 	// Create client game data
 	clientGameData := map[string]interface{}{
-		"gameData": gameData,
+		"gameData": g.data,
 		"settings": settings,
 	}
 
 	// Broadcast game start to all clients
 	room.BroadcastToRoom(gameRoom, map[string]interface{}{
 		"type":     "platformGameStarted",
-		"gameType": "redenvelope",
+		"gameType": GameType,
 		"data":     clientGameData,
 	})
 
 	log.Printf("[REDENVELOPE] Red envelope game started for room %s with %d seconds", gameRoom.ID, settings.Duration)
 }
+
+// playerResults converts the final leaderboard into recorded player results
+func playerResults(scores []PlayerScore) []store.PlayerResult {
+	players := make([]store.PlayerResult, 0, len(scores))
+	for _, score := range scores {
+		players = append(players, store.PlayerResult{
+			PlayerID:  score.PlayerID,
+			Nickname:  score.Nickname,
+			Rank:      score.Rank,
+			Score:     score.Score,
+			Collected: score.CollectedCount,
+		})
+	}
+	return players
+}
+
+// maxEnvelopeValue is the most points a single server-dropped envelope can hold
+const maxEnvelopeValue = 10
+
+// maxReportedEnvelopeValue is the most points an envelope dropped by a client can hold
+const maxReportedEnvelopeValue = 100
+
+// scoreValidator bounds the scores clients report to the best envelope at
+// every spawn, plus a full screen of them
+func (g *Game) scoreValidator() scorecheck.Validator {
+	return scorecheck.Limits{
+		MaxPointsPerSecond: maxReportedEnvelopeValue * 1000 / float64(g.settings.SpawnInterval),
+		Burst:              maxReportedEnvelopeValue * g.settings.EnvelopeCount,
+	}
+}
+
+// plausibleCollected bounds the envelope count a client reports: no more
+// than a full screen plus one per spawn since the start, and no more than
+// the accepted score, as every envelope is worth at least a point
+func (g *Game) plausibleCollected(reported, score int, elapsed time.Duration) int {
+	limit := g.settings.EnvelopeCount + int(elapsed/(time.Duration(g.settings.SpawnInterval)*time.Millisecond))
+	if limit > score {
+		limit = score
+	}
+	if reported > limit {
+		return limit
+	}
+	return reported
+}
+
+// spawnEnvelope clears expired envelopes and drops a new one while there is room on screen
+func (g *Game) spawnEnvelope() {
+	if g.ended {
+		return
+	}
+	now := time.Now()
+
+	active := g.data.Envelopes[:0]
+	for _, envelope := range g.data.Envelopes {
+		if envelope.ExpiresAt.After(now) {
+			active = append(active, envelope)
+		}
+	}
+	g.data.Envelopes = active
+
+	if len(g.data.Envelopes) >= g.settings.EnvelopeCount {
+		return
+	}
+
+	// Positions are in percent, kept clear of the screen edges
+	g.nextEnvelopeID++
+	envelope := RedEnvelope{
+		ID:        fmt.Sprintf("e%d", g.nextEnvelopeID),
+		X:         5 + g.rng.Float64()*90,
+		Y:         5 + g.rng.Float64()*90,
+		Value:     1 + g.rng.Intn(maxEnvelopeValue),
+		SpawnTime: now,
+		ExpiresAt: now.Add(time.Duration(g.settings.EnvelopeLifetime) * time.Millisecond),
+	}
+	g.data.Envelopes = append(g.data.Envelopes, envelope)
+
+	room.BroadcastToRoom(g.room, map[string]interface{}{
+		"type":     "redenvelope-spawn",
+		"envelope": envelope,
+	})
+}
+
+// grabEnvelope settles a grab. Grabs run on the room's event loop one at a
+// time, so the first valid grab takes the envelope and later ones are refused.
+func (g *Game) grabEnvelope(client *core.Client, envelopeID string) error {
+	index := -1
+	for i, envelope := range g.data.Envelopes {
+		if envelope.ID == envelopeID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return core.NewError(core.ErrCodeInvalidPayload, "envelope %s was already taken", envelopeID)
+	}
+	envelope := g.data.Envelopes[index]
+	if time.Now().After(envelope.ExpiresAt) {
+		return core.NewError(core.ErrCodeInvalidPayload, "envelope %s has expired", envelopeID)
+	}
+
+	g.data.Envelopes = append(g.data.Envelopes[:index], g.data.Envelopes[index+1:]...)
+	g.collected[client.ID]++
+
+	room.BroadcastToRoom(g.room, map[string]interface{}{
+		"type":       "redenvelope-grabbed",
+		"envelopeId": envelopeID,
+		"playerId":   client.ID,
+		"nickname":   client.Nickname,
+		"value":      envelope.Value,
+	})
+	return g.updatePlayerScore(client, client.Score+envelope.Value)
+}
@@ -8,27 +8,65 @@ type GameSettings struct {
 	SpawnInterval    int `json:"spawnInterval"`    // Envelope spawn interval in ms
 	EnvelopeLifetime int `json:"envelopeLifetime"` // How long envelopes stay on screen in ms
 	EnvelopeCount    int `json:"envelopeCount"`    // Max envelopes on screen
+	// The server drops the envelopes and settles the grabs
+	Authoritative bool `json:"authoritative,omitempty"`
+	// Seeds the drops; kept from clients, who could predict every envelope from it
+	Seed int64 `json:"-"`
 }
 
 // GameData represents the current state of the red envelope game
 type GameData struct {
 	TimeLeft int  `json:"timeLeft"` // Time remaining in seconds
 	Active   bool `json:"active"`   // Whether the game is currently active
+	// Envelopes on screen in authoritative games
+	Envelopes []RedEnvelope `json:"envelopes,omitempty"`
 }
 
 // RedEnvelope represents a red envelope in the game
 type RedEnvelope struct {
-	ID       string  `json:"id"`
-	X        float64 `json:"x"`
-	Y        float64 `json:"y"`
-	Value    int     `json:"value"`
+	ID        string    `json:"id"`
+	X         float64   `json:"x"`
+	Y         float64   `json:"y"`
+	Value     int       `json:"value"`
 	SpawnTime time.Time `json:"spawnTime"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 // PlayerScore represents a player's score and ranking
 type PlayerScore struct {
+	PlayerID       string `json:"playerId"`
 	Nickname       string `json:"nickname"`
+	Team           string `json:"team,omitempty"`
 	Score          int    `json:"score"`
 	Rank           int    `json:"rank"`
 	CollectedCount int    `json:"collectedCount"`
-}
\ No newline at end of file
+}
+
+// Snapshot represents the red envelope game state returned by Game.Snapshot
+type Snapshot struct {
+	GameType string        `json:"gameType"`
+	Settings GameSettings  `json:"gameSettings"`
+	GameData GameData      `json:"gameData"`
+	Players  []PlayerScore `json:"players"`
+}
+
+// StartPayload represents the settings sent by the host in redenvelope-startgame
+type StartPayload struct {
+	Duration         int   `json:"duration" validate:"omitempty,min=1,max=3600"`
+	SpawnInterval    int   `json:"spawnInterval" validate:"omitempty,min=100"` // Drives the server spawner in authoritative games
+	EnvelopeLifetime int   `json:"envelopeLifetime" validate:"omitempty,min=1"`
+	EnvelopeCount    int   `json:"envelopeCount" validate:"omitempty,min=1,max=100"`
+	Authoritative    bool  `json:"authoritative"`
+	Seed             int64 `json:"seed"` // Replays a recorded game; zero draws a new seed
+}
+
+// GrabPayload represents a redenvelope-grab message from a player in an authoritative game
+type GrabPayload struct {
+	EnvelopeID string `json:"envelopeId" validate:"required"`
+}
+
+// ScoreUpdatePayload represents a redenvelope-scoreupdate message from a player
+type ScoreUpdatePayload struct {
+	TotalScore     *int `json:"totalScore" validate:"required,min=0"`
+	CollectedCount int  `json:"collectedCount" validate:"min=0"`
+}
@@ -0,0 +1,111 @@
+package whackmole
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"gaming-platform/core"
+	"gaming-platform/platform/room"
+)
+
+// GameType is the registered type name of the whack-a-mole game
+const GameType = "whackmole"
+
+// Game is a whack-a-mole game instance owned by a room
+type Game struct {
+	room      *core.Room
+	data      GameData
+	settings  GameSettings
+	ended     bool
+	startedAt time.Time
+	stopTimer func()
+
+	// Authoritative mode state
+	rng         *rand.Rand // Seeded with settings.Seed
+	stopSpawner func()
+	nextMoleID  int
+	hits        map[string]map[string]bool // Mole ID to the player IDs that hit it
+	hitCounts   map[string]int             // Moles hit per player ID
+}
+
+// NewGame creates a whack-a-mole game bound to the given room
+func NewGame(gameRoom *core.Room) core.Game {
+	return &Game{
+		room: gameRoom,
+	}
+}
+
+// Type returns the game type
+func (g *Game) Type() string {
+	return GameType
+}
+
+// OnPlayerJoin sends the running game settings to a late joining player
+func (g *Game) OnPlayerJoin(client *core.Client) {
+	if !g.data.IsActive || client.IsHost {
+		return
+	}
+
+	room.SendToClient(client, map[string]interface{}{
+		"type":     "platformGameStarted",
+		"gameType": GameType,
+		"gameData": map[string]interface{}{
+			"gameSettings": g.settings,
+			"gameTime":     g.room.GameTime,
+		},
+		"message": "Whack-a-mole game started!",
+	})
+}
+
+// OnPlayerLeave refreshes the leaderboard after a player leaves
+func (g *Game) OnPlayerLeave(client *core.Client) {
+	if g.ended || client.IsHost || client.IsSpectator {
+		return
+	}
+	room.BroadcastToRoom(g.room, room.WithTeamStandings(g.room, map[string]interface{}{
+		"type":    "mole-leaderboard",
+		"players": g.leaderboard(),
+	}))
+}
+
+// Snapshot returns the current whack-a-mole game state
+func (g *Game) Snapshot() interface{} {
+	data := g.data
+	data.TimeRemaining = g.room.GameTime
+	return Snapshot{
+		GameType: GameType,
+		Settings: g.settings,
+		GameData: data,
+		Players:  g.leaderboard(),
+	}
+}
+
+// OnResume pushes the expiry of the moles on screen back by the pause, so
+// they stay up as long as they would have without it
+func (g *Game) OnResume(paused time.Duration) {
+	for i := range g.data.Moles {
+		g.data.Moles[i].SpawnTime += paused.Milliseconds()
+		g.data.Moles[i].ExpiresAt += paused.Milliseconds()
+	}
+}
+
+// End stops the game timer and broadcasts the final scores
+func (g *Game) End() {
+	if g.ended {
+		return
+	}
+	g.stop()
+	g.handleGameEnd()
+}
+
+// stop stops the game timer and the mole spawner
+func (g *Game) stop() {
+	if g.stopTimer != nil {
+		g.stopTimer()
+		log.Printf("[WHACKMOLE] Timer stopped for room %s", g.room.ID)
+	}
+	if g.stopSpawner != nil {
+		g.stopSpawner()
+	}
+}
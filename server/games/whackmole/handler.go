@@ -4,152 +4,129 @@ import (
 	"log"
 
 	"gaming-platform/core"
-	"gaming-platform/core/interfaces"
+	"gaming-platform/core/message"
 	"gaming-platform/platform/room"
+	"gaming-platform/platform/scorecheck"
 )
 
-// registrar holds the registration interface
-var registrar interfaces.RegistrationInterface
-
-// SetRegistrar sets the registration interface
-func SetRegistrar(r interfaces.RegistrationInterface) {
-	registrar = r
-	// Register all handlers when registrar is set
-	registerHandlers()
-}
-
-// registerHandlers registers all whack-a-mole game message handlers
-func registerHandlers() {
-	if registrar == nil {
-		return
-	}
-	// Register whack-a-mole game specific message types
-	registrar.RegisterHandler("mole-scoreupdate", HandleWhackAMoleGameMessage)
-	// Register whack-a-mole game start handler
-	registrar.RegisterHandler("mole-startgame", HandleWhackAMoleGameMessage)
+// init registers the whack-a-mole game with the platform
+func init() {
+	message.RegisterGame(core.GameDefinition{
+		Type:         GameType,
+		StartMessage: "mole-startgame",
+		StartPayload: StartPayload{},
+		Messages: map[string]interface{}{
+			"mole-scoreupdate": ScoreUpdatePayload{},
+			"mole-hit":         HitPayload{},
+		},
+		Factory: NewGame,
+	})
 }
 
-// HandleWhackAMoleGameMessage processes whack-a-mole game specific messages
-func HandleWhackAMoleGameMessage(gameRoom *core.Room, client *core.Client, message core.Message) {
+// HandleMessage processes whack-a-mole game specific messages
+func (g *Game) HandleMessage(client *core.Client, message core.Message) error {
 	switch message.Type {
 	case "mole-scoreupdate":
-		handleScoreUpdate(gameRoom, client, message)
-	case "mole-startgame", "hostStartGame":
-		HandleWhackAMoleHostStartGame(gameRoom, client, message)
+		return g.handleScoreUpdate(client, message)
+	case "mole-hit":
+		return g.handleHit(client, message)
 	default:
-		log.Printf("[WHACKMOLE] Unknown whack-a-mole game message type: %s", message.Type)
+		return core.NewError(core.ErrCodeUnknownMessageType, "unknown whack-a-mole game message type: %s", message.Type)
 	}
 }
 
-// HandleWhackAMoleHostStartGame handles whack-a-mole game start messages
-func HandleWhackAMoleHostStartGame(gameRoom *core.Room, client *core.Client, message core.Message) {
-	log.Printf("[WHACKMOLE] Starting whack-a-mole game for host %s", client.Nickname)
-	// Call the game start logic directly to avoid recursion
-	handleHostStartGame(gameRoom, client, message)
-}
-
 // handleScoreUpdate processes score update messages from client
-func handleScoreUpdate(gameRoom *core.Room, client *core.Client, message core.Message) {
+func (g *Game) handleScoreUpdate(client *core.Client, message core.Message) error {
 	log.Printf("[WHACKMOLE] Processing scoreUpdate from %s", client.Nickname)
 
-	// Get game instance from room
-	gameInterface := gameRoom.GameData
-	if gameInterface == nil {
-		log.Printf("[WHACKMOLE] No active game in room %s", gameRoom.ID)
-		return
-	}
-
-	// Extract score data from message
-	dataMap, ok := message.Data.(map[string]interface{})
-	if !ok {
-		log.Printf("[WHACKMOLE] Invalid message data format")
-		return
-	}
+	payload := message.Data.(*ScoreUpdatePayload)
 
-	// Extract data from dataMap['data']
-	rawData, ok := dataMap["data"]
-	if !ok {
-		log.Printf("[WHACKMOLE] 'data' key not found in message data")
-		return
+	// Authoritative games score hits on the server
+	if g.settings.Authoritative {
+		return core.NewError(core.ErrCodeInvalidMessage, "scores are kept by the server in this game; send mole-hit instead")
 	}
-	// extract totalScore from rawData
-	rawScore, ok := rawData.(map[string]interface{})
-	if !ok {
-		log.Printf("[WHACKMOLE] 'data' is not a map")
-		return
+	if !g.room.PlayerClients[client] {
+		return core.NewError(core.ErrCodeNotPlayer, "only players keep a score")
 	}
-	totalScore, ok := rawScore["totalScore"].(float64)
-	if !ok {
-		log.Printf("[WHACKMOLE] 'totalScore' key not found in message data")
-		return
+
+	// Implausible scores are refused or cut down and reported to the host
+	totalScore, err := scorecheck.Check(g.room, GameType, g.scoreValidator(), client, scorecheck.Submission{
+		Previous: client.Score,
+		Reported: *payload.TotalScore,
+		Elapsed:  g.room.PlayTime(g.startedAt),
+		Ended:    g.ended,
+	})
+	if err != nil {
+		return err
 	}
 
 	// Update player score in game
-	UpdatePlayerScore(gameRoom, client.Nickname, client.Nickname, int(totalScore))
+	if err := g.updatePlayerScore(client, totalScore); err != nil {
+		return err
+	}
 
-	// Calculate and send updated leaderboard to host
-	leaderboard := calculateLeaderboard(gameRoom)
+	// Calculate and send updated leaderboard to host and spectators
+	leaderboard := g.leaderboard()
 
-	room.BroadcastToHost(gameRoom, map[string]interface{}{
+	room.BroadcastToHostAndSpectators(g.room, room.WithTeamStandings(g.room, map[string]interface{}{
 		"type":        "mole-leaderboard",
 		"leaderboard": leaderboard,
-	})
+	}))
 
-	log.Printf("[WHACKMOLE] Player %s updated total score to %d", client.Nickname, int(totalScore))
+	log.Printf("[WHACKMOLE] Player %s updated total score to %d", client.Nickname, totalScore)
+	return nil
 }
 
-// handleHostStartGame processes host start game messages
-func handleHostStartGame(gameRoom *core.Room, client *core.Client, message core.Message) {
-	// Check if client is host
-	if !client.IsHost {
-		log.Printf("[WHACKMOLE] Non-host client %s attempted to start game", client.Nickname)
-		return
-	}
+// handleHit processes a mole hit in an authoritative game
+func (g *Game) handleHit(client *core.Client, message core.Message) error {
+	payload := message.Data.(*HitPayload)
 
-	// Extract message data
-	dataMap, ok := message.Data.(map[string]interface{})
-	if !ok {
-		log.Printf("[WHACKMOLE] Invalid message data format")
-		return
+	if g.ended {
+		return core.NewError(core.ErrCodeGameEnded, "the game has already ended")
 	}
-
-	// Extract game settings with defaults
-	duration := 60 // default 60 seconds
-	if d, exists := dataMap["duration"]; exists {
-		if dFloat, ok := d.(float64); ok {
-			duration = int(dFloat)
-		}
+	if !g.settings.Authoritative {
+		return core.NewError(core.ErrCodeInvalidMessage, "moles are spawned by the clients in this game; send mole-scoreupdate instead")
 	}
-
-	moleSpawnInterval := 1000 // default 1 second
-	if msi, exists := dataMap["moleSpawnInterval"]; exists {
-		if msiFloat, ok := msi.(float64); ok {
-			moleSpawnInterval = int(msiFloat)
-		}
+	if !g.room.PlayerClients[client] {
+		return core.NewError(core.ErrCodeNotPlayer, "only players can hit moles")
 	}
 
-	moleLifetime := 2000 // default 2 seconds
-	if ml, exists := dataMap["moleLifetime"]; exists {
-		if mlFloat, ok := ml.(float64); ok {
-			moleLifetime = int(mlFloat)
-		}
+	return g.hitMole(client, payload.MoleID)
+}
+
+// Start starts a whack-a-mole game with the settings from the host's start message
+func (g *Game) Start(message core.Message) error {
+	log.Printf("[WHACKMOLE] Starting whack-a-mole game in room %s", g.room.ID)
+
+	payload := message.Data.(*StartPayload)
+	if payload.GameSettings != nil {
+		payload = payload.GameSettings
 	}
 
-	moleCount := 9 // default 9 holes
-	if mc, exists := dataMap["moleCount"]; exists {
-		if mcFloat, ok := mc.(float64); ok {
-			moleCount = int(mcFloat)
+	// Fall back to defaults for settings the host left out
+	withDefault := func(value, defaultValue int) int {
+		if value > 0 {
+			return value
 		}
+		return defaultValue
 	}
 
 	// Create game settings
 	settings := GameSettings{
-		Duration:          duration,
-		MoleSpawnInterval: moleSpawnInterval,
-		MoleLifetime:      moleLifetime,
-		MoleCount:         moleCount,
+		Duration:          withDefault(payload.Duration, 60),            // default 60 seconds
+		MoleSpawnInterval: withDefault(payload.MoleSpawnInterval, 1000), // default 1 second
+		MoleLifetime:      withDefault(payload.MoleLifetime, 2000),      // default 2 seconds
+		MoleCount:         withDefault(payload.MoleCount, 9),            // default 9 holes
+		Authoritative:     payload.Authoritative,
+		Seed:              payload.Seed,
+	}
+
+	// Every game draws its own seed from the room unless the host replays one
+	if settings.Seed == 0 {
+		settings.Seed = g.room.Rand.Int63()
 	}
 
 	// Start the game
-	StartWhackAMoleGame(gameRoom, settings)
+	g.startWhackAMoleGame(settings)
+	return nil
 }
@@ -1,74 +1,85 @@
 package whackmole
 
 import (
-	"encoding/json"
+	"fmt"
 	"log"
+	"math"
+	"math/rand"
 	"sort"
 	"time"
 
 	"gaming-platform/core"
+	"gaming-platform/platform/results"
 	"gaming-platform/platform/room"
+	"gaming-platform/platform/scorecheck"
+	"gaming-platform/platform/store"
 )
 
-// startTimeUpdates begins sending time updates every second
-func startTimeUpdates(gameRoom *core.Room, duration int) {
-	ticker := time.NewTicker(1 * time.Second)
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				if gameRoom.GameEnded {
-					return
-				}
-
-				// Decrease game time
-				gameRoom.GameTime--
-
-				if gameRoom.GameTime < 0 {
-					gameRoom.GameTime = 0
-				}
-
-				// Send time update
-				room.BroadcastToRoom(gameRoom, map[string]interface{}{
-					"type":     "mole-timeupdate",
-					"gameType": "whackmole",
-					"timeLeft": gameRoom.GameTime,
-				})
-
-				// Check if time is up
-				if gameRoom.GameTime <= 0 {
-					log.Printf("[WHACKMOLE] Time up for room %s, ending game", gameRoom.ID)
-					HandleGameEnd(gameRoom)
-					return
-				}
-			case <-gameRoom.StopChan:
-				return
-			}
-		}
-	}()
+// tick counts the game time down, sends time updates and ends the game at zero
+func (g *Game) tick() {
+	gameRoom := g.room
+	if g.ended {
+		return
+	}
+
+	// Decrease game time
+	gameRoom.GameTime--
+
+	if gameRoom.GameTime < 0 {
+		gameRoom.GameTime = 0
+	}
+	gameRoom.LogTick(gameRoom.GameTime)
+
+	// Send time update
+	room.BroadcastToRoom(gameRoom, map[string]interface{}{
+		"type":     "mole-timeupdate",
+		"gameType": GameType,
+		"timeLeft": gameRoom.GameTime,
+	})
+
+	// Check if time is up
+	if gameRoom.GameTime <= 0 {
+		log.Printf("[WHACKMOLE] Time up for room %s, ending game", gameRoom.ID)
+		g.End()
+	}
 }
 
-// UpdatePlayerScore updates a player's total score
-func UpdatePlayerScore(gameRoom *core.Room, playerID, nickname string, totalScore int) {
-	if gameRoom.GameEnded {
-		return
+// updatePlayerScore updates a player's total score
+func (g *Game) updatePlayerScore(client *core.Client, totalScore int) error {
+	gameRoom := g.room
+	if g.ended {
+		return core.NewError(core.ErrCodeGameEnded, "the game has already ended")
 	}
 
-	// Find the client and update their score
-	for client := range gameRoom.PlayerClients {
-		if client.Nickname == nickname {
-			client.Score = totalScore
-			log.Printf("[WHACKMOLE] Player %s score updated to %d in room %s", nickname, totalScore, gameRoom.ID)
-			break
-		}
+	// Only players in the room keep a score
+	if _, isPlayer := gameRoom.PlayerClients[client]; !isPlayer {
+		return core.NewError(core.ErrCodeNotPlayer, "only players keep a score")
 	}
+	client.Score = totalScore
+	gameRoom.LogScore(client)
+	log.Printf("[WHACKMOLE] Player %s score updated to %d in room %s", client.Nickname, totalScore, gameRoom.ID)
+
 	// Broadcast leaderboard update
-	leaderboard := calculateLeaderboard(gameRoom)
-	room.BroadcastToRoom(gameRoom, map[string]interface{}{
+	leaderboard := g.leaderboard()
+	room.BroadcastToRoom(gameRoom, room.WithTeamStandings(gameRoom, map[string]interface{}{
 		"type":    "mole-leaderboard",
 		"players": leaderboard,
-	})
+	}))
+	return nil
+}
+
+// leaderboard ranks the players and adds the moles each one hit. Clients
+// that spawn their own moles report only a score, so their hits follow from it.
+func (g *Game) leaderboard() []PlayerScore {
+	players := calculateLeaderboard(g.room)
+	for i := range players {
+		if g.settings.Authoritative {
+			players[i].HitCount = g.hitCounts[players[i].PlayerID]
+		} else {
+			players[i].HitCount = players[i].Score / pointsPerHit
+		}
+	}
+	return players
 }
 
 // calculateLeaderboard calculates and returns player rankings
@@ -78,9 +89,10 @@ func calculateLeaderboard(gameRoom *core.Room) []PlayerScore {
 	// Collect player scores from PlayerClients
 	for client := range gameRoom.PlayerClients {
 		players = append(players, PlayerScore{
+			PlayerID: client.ID,
 			Nickname: client.Nickname,
+			Team:     client.Team,
 			Score:    client.Score,
-			HitCount: 0, // Not tracking hit count in simplified version
 		})
 	}
 
@@ -97,51 +109,49 @@ func calculateLeaderboard(gameRoom *core.Room) []PlayerScore {
 	return players
 }
 
-// HandleGameEnd processes the end of a whack-a-mole game
-func HandleGameEnd(gameRoom *core.Room) {
+// handleGameEnd processes the end of a whack-a-mole game
+func (g *Game) handleGameEnd() {
+	gameRoom := g.room
 	log.Printf("[WHACKMOLE] Game ended for room %s", gameRoom.ID)
 
+	g.ended = true
+	g.data.IsActive = false
 	gameRoom.GameEnded = true
 	gameRoom.WaitingForPlayers = false
 
-	// Stop the game timer
-	if gameRoom.Timer != nil {
-		gameRoom.Timer.Stop()
-	}
-
 	// Calculate final scores and rankings
-	leaderboard := calculateLeaderboard(gameRoom)
+	leaderboard := g.leaderboard()
+
+	// Record the game before announcing it so clients can look it up
+	resultID := results.Record(gameRoom, GameType, g.settings, g.settings.Seed, g.startedAt, playerResults(leaderboard))
 
 	// Broadcast game end to all clients
-	room.BroadcastToRoom(gameRoom, map[string]interface{}{
-		"type":    "mole-gameend",
-		"players": leaderboard,
-		"message": "Whack-a-mole game completed!",
-	})
+	room.BroadcastToRoom(gameRoom, room.WithTeamStandings(gameRoom, map[string]interface{}{
+		"type":     "mole-gameend",
+		"players":  leaderboard,
+		"message":  "Whack-a-mole game completed!",
+		"resultId": resultID,
+	}))
 
 	log.Printf("[WHACKMOLE] Final scores for room %s: %+v", gameRoom.ID, leaderboard)
 }
 
-// StartWhackAMoleGame initializes and starts a whack-a-mole game
-func StartWhackAMoleGame(gameRoom *core.Room, settings GameSettings) {
+// startWhackAMoleGame initializes and starts a whack-a-mole game
+func (g *Game) startWhackAMoleGame(settings GameSettings) {
+	gameRoom := g.room
 	log.Printf("[WHACKMOLE] Starting whack-a-mole game for room %s with settings: %+v", gameRoom.ID, settings)
 
 	// Initialize game data
-	gameData := GameData{
+	g.settings = settings
+	g.data = GameData{
 		TimeRemaining: settings.Duration,
 		IsActive:      true,
 		Moles:         make([]MoleState, 0),
 	}
 
-	// Store game data in room
-	gameDataBytes, err := json.Marshal(gameData)
-	if err != nil {
-		log.Printf("Error marshaling game data: %v", err)
-		return
-	}
+	g.startedAt = time.Now()
 
 	// Set game state
-	gameRoom.GameData = gameDataBytes
 	gameRoom.GameStarted = true
 	gameRoom.GameEnded = false
 	gameRoom.WaitingForPlayers = false
@@ -151,22 +161,12 @@ func StartWhackAMoleGame(gameRoom *core.Room, settings GameSettings) {
 	for client := range gameRoom.PlayerClients {
 		client.Score = 0
 	}
+	g.rng = rand.New(rand.NewSource(settings.Seed))
+	g.hits = make(map[string]map[string]bool)
+	g.hitCounts = make(map[string]int)
 
-	// Start game timer
-	gameRoom.Timer = time.NewTicker(1 * time.Second)
-	go func() {
-		for {
-			select {
-			case <-gameRoom.Timer.C:
-				// This will be handled by startTimeUpdates
-			case <-gameRoom.StopChan:
-				return
-			}
-		}
-	}()
-
-	// Start time update ticker
-	startTimeUpdates(gameRoom, settings.Duration)
+	// Start time update ticker on the room's event loop
+	g.stopTimer = gameRoom.Every(1*time.Second, g.tick)
 
 	// Create client game data
 	clientGameData := map[string]interface{}{
@@ -174,13 +174,141 @@ func StartWhackAMoleGame(gameRoom *core.Room, settings GameSettings) {
 		"gameTime":     settings.Duration,
 	}
 
+	// In authoritative games the server decides where and when moles appear
+	if settings.Authoritative {
+		g.data.Holes = newHoles(settings.MoleCount)
+		clientGameData["holes"] = g.data.Holes
+		g.stopSpawner = gameRoom.Every(time.Duration(settings.MoleSpawnInterval)*time.Millisecond, g.spawnMole)
+	}
+
 	// Notify all clients that the game has started
 	room.BroadcastToRoom(gameRoom, map[string]interface{}{
 		"type":     "platformGameStarted",
-		"gameType": "whackmole",
+		"gameType": GameType,
 		"gameData": clientGameData,
 		"message":  "Whack-a-mole game started!",
 	})
 
 	log.Printf("[WHACKMOLE] Whack-a-mole game started for room %s with %d seconds", gameRoom.ID, settings.Duration)
 }
+
+// playerResults converts the final leaderboard into recorded player results
+func playerResults(scores []PlayerScore) []store.PlayerResult {
+	players := make([]store.PlayerResult, 0, len(scores))
+	for _, score := range scores {
+		players = append(players, store.PlayerResult{
+			PlayerID:  score.PlayerID,
+			Nickname:  score.Nickname,
+			Rank:      score.Rank,
+			Score:     score.Score,
+			Collected: score.HitCount,
+		})
+	}
+	return players
+}
+
+// pointsPerHit is the score for each mole hit
+const pointsPerHit = 10
+
+// scoreValidator bounds the scores clients report. Clients pick their own
+// spawn pace, so they may hit up to twice as many moles as the settings
+// spawn, plus every hole at once.
+func (g *Game) scoreValidator() scorecheck.Validator {
+	return scorecheck.Limits{
+		MaxPointsPerSecond: 2 * pointsPerHit * 1000 / float64(g.settings.MoleSpawnInterval),
+		Burst:              pointsPerHit * g.settings.MoleCount,
+	}
+}
+
+// newHoles lays the mole holes out on a grid, positions given in percent
+func newHoles(count int) []MoleHole {
+	columns := int(math.Ceil(math.Sqrt(float64(count))))
+	rows := (count + columns - 1) / columns
+
+	holes := make([]MoleHole, count)
+	for i := range holes {
+		holes[i] = MoleHole{
+			ID: i,
+			X:  (2*(i%columns) + 1) * 50 / columns,
+			Y:  (2*(i/columns) + 1) * 50 / rows,
+		}
+	}
+	return holes
+}
+
+// spawnMole clears expired moles and pops a new one up in a free hole
+func (g *Game) spawnMole() {
+	if g.ended {
+		return
+	}
+	now := time.Now().UnixMilli()
+
+	// Moles past their lifetime go back down and free their hole
+	active := g.data.Moles[:0]
+	for _, mole := range g.data.Moles {
+		if mole.ExpiresAt > now {
+			active = append(active, mole)
+		} else {
+			g.data.Holes[mole.Position].IsOccupied = false
+			delete(g.hits, mole.ID)
+		}
+	}
+	g.data.Moles = active
+
+	free := []int{}
+	for _, hole := range g.data.Holes {
+		if !hole.IsOccupied {
+			free = append(free, hole.ID)
+		}
+	}
+	if len(free) == 0 {
+		return
+	}
+
+	g.nextMoleID++
+	mole := MoleState{
+		ID:        fmt.Sprintf("m%d", g.nextMoleID),
+		Position:  free[g.rng.Intn(len(free))],
+		SpawnTime: now,
+		ExpiresAt: now + int64(g.settings.MoleLifetime),
+	}
+	g.data.Holes[mole.Position].IsOccupied = true
+	g.data.Moles = append(g.data.Moles, mole)
+
+	room.BroadcastToRoom(g.room, map[string]interface{}{
+		"type": "mole-spawn",
+		"mole": mole,
+	})
+}
+
+// hitMole scores a player's hit on a mole that is still up.
+// Every player may hit each mole once.
+func (g *Game) hitMole(client *core.Client, moleID string) error {
+	var mole *MoleState
+	for i := range g.data.Moles {
+		if g.data.Moles[i].ID == moleID {
+			mole = &g.data.Moles[i]
+			break
+		}
+	}
+	if mole == nil || time.Now().UnixMilli() > mole.ExpiresAt {
+		return core.NewError(core.ErrCodeInvalidPayload, "mole %s is not up", moleID)
+	}
+	if g.hits[moleID][client.ID] {
+		return core.NewError(core.ErrCodeInvalidPayload, "mole %s was already hit", moleID)
+	}
+
+	if g.hits[moleID] == nil {
+		g.hits[moleID] = make(map[string]bool)
+	}
+	g.hits[moleID][client.ID] = true
+	g.hitCounts[client.ID]++
+
+	room.SendToClient(client, map[string]interface{}{
+		"type":     "mole-hitconfirmed",
+		"moleId":   moleID,
+		"score":    client.Score + pointsPerHit,
+		"hitCount": g.hitCounts[client.ID],
+	})
+	return g.updatePlayerScore(client, client.Score+pointsPerHit)
+}
@@ -6,6 +6,10 @@ type GameSettings struct {
 	MoleSpawnInterval int `json:"moleSpawnInterval"` // Interval between mole spawns in milliseconds
 	MoleLifetime      int `json:"moleLifetime"`      // How long a mole stays visible in milliseconds
 	MoleCount         int `json:"moleCount"`         // Number of mole holes
+	// The server spawns the moles and scores the hits
+	Authoritative bool `json:"authoritative,omitempty"`
+	// Seeds the spawn schedule; kept from clients, who could predict every mole from it
+	Seed int64 `json:"-"`
 }
 
 // GameData represents the current state of the game
@@ -13,6 +17,7 @@ type GameData struct {
 	TimeRemaining int         `json:"timeRemaining"`
 	IsActive      bool        `json:"isActive"`
 	Moles         []MoleState `json:"moles"`
+	Holes         []MoleHole  `json:"holes,omitempty"` // Hole layout in authoritative games
 }
 
 // MoleHole represents a hole where moles can appear
@@ -25,15 +30,48 @@ type MoleHole struct {
 
 // MoleState represents a mole that has spawned
 type MoleState struct {
-	ID       string `json:"id"`       // Unique mole ID
-	Position int    `json:"position"` // Hole ID where the mole is located
-	SpawnTime int64 `json:"spawnTime"` // When the mole spawned (timestamp)
+	ID        string `json:"id"`        // Unique mole ID
+	Position  int    `json:"position"`  // Hole ID where the mole is located
+	SpawnTime int64  `json:"spawnTime"` // When the mole spawned (timestamp)
+	ExpiresAt int64  `json:"expiresAt"` // When the mole goes back down (timestamp)
 }
 
 // PlayerScore represents a player's score for ranking
 type PlayerScore struct {
+	PlayerID string `json:"playerId"`
 	Nickname string `json:"nickname"`
+	Team     string `json:"team,omitempty"`
 	Score    int    `json:"score"`
 	Rank     int    `json:"rank"`
 	HitCount int    `json:"hitCount"`
-}
\ No newline at end of file
+}
+
+// Snapshot represents the whack-a-mole game state returned by Game.Snapshot
+type Snapshot struct {
+	GameType string        `json:"gameType"`
+	Settings GameSettings  `json:"gameSettings"`
+	GameData GameData      `json:"gameData"`
+	Players  []PlayerScore `json:"players"`
+}
+
+// StartPayload represents the settings sent by the host in mole-startgame.
+// Settings may be sent at the top level or nested under gameSettings.
+type StartPayload struct {
+	Duration          int           `json:"duration" validate:"omitempty,min=1,max=3600"`
+	MoleSpawnInterval int           `json:"moleSpawnInterval" validate:"omitempty,min=100"` // Drives the server spawner in authoritative games
+	MoleLifetime      int           `json:"moleLifetime" validate:"omitempty,min=1"`
+	MoleCount         int           `json:"moleCount" validate:"omitempty,min=1,max=25"`
+	Authoritative     bool          `json:"authoritative"`
+	Seed              int64         `json:"seed"` // Replays a recorded game; zero draws a new seed
+	GameSettings      *StartPayload `json:"gameSettings,omitempty"`
+}
+
+// HitPayload represents a mole-hit message from a player in an authoritative game
+type HitPayload struct {
+	MoleID string `json:"moleId" validate:"required"`
+}
+
+// ScoreUpdatePayload represents a mole-scoreupdate message from a player
+type ScoreUpdatePayload struct {
+	TotalScore *int `json:"totalScore" validate:"required,min=0"`
+}
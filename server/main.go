@@ -1,13 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"gaming-platform/config"
 	"gaming-platform/core/websocket"
+	"gaming-platform/platform/account"
 	"gaming-platform/platform/api"
-	// Import core message to trigger game handler registration
-	_ "gaming-platform/core/message"
+	"gaming-platform/platform/results"
+	"gaming-platform/platform/room"
+	"gaming-platform/platform/stats"
+	"gaming-platform/platform/store"
+	// Import game modules to trigger game registration
+	_ "gaming-platform/games"
+	// Import the tournament and team modules to register their host messages
+	_ "gaming-platform/platform/teams"
+	_ "gaming-platform/platform/tournament"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,6 +30,23 @@ import (
 func main() {
 	log.Println("Starting Gaming Platform Server...")
 
+	// Load configuration from environment variables
+	cfg := config.Load()
+	log.Printf("Configuration: %s", cfg)
+
+	// Open the store for users, rooms and game results
+	dataStore, err := store.Open(cfg.StoreBackend, cfg.StorePath)
+	if err != nil {
+		log.Fatalf("Failed to open %s store: %v", cfg.StoreBackend, err)
+	}
+	defer dataStore.Close()
+	account.SetUserStore(dataStore)
+	room.SetStore(dataStore)
+	results.SetStore(dataStore)
+	stats.SetStore(dataStore)
+	api.SetStore(dataStore)
+	log.Printf("Using %s store", cfg.StoreBackend)
+
 	// Create Gin router
 	r := gin.Default()
 
@@ -41,6 +73,19 @@ func main() {
 	r.GET("/api/rooms/:roomId/players", api.GetPlayerList)
 	r.GET("/api/rooms/:roomId/info", api.GetRoomInfo)
 	r.GET("/api/rooms", api.GetRoomList)
+	r.POST("/api/rooms", api.CreateRoom)
+	r.POST("/api/rooms/:roomId/join", api.JoinRoom)
+	r.GET("/api/rooms/:roomId/results", api.GetRoomResults)
+	r.GET("/api/results/:id", api.GetResult)
+	r.GET("/api/results/:id/events", api.GetResultEvents)
+	r.GET("/api/results/:id/replay", api.ReplayResult)
+	r.GET("/api/players/:id/stats", api.GetPlayerStats)
+
+	// Account routes
+	r.POST("/api/auth/register", api.Register)
+	r.POST("/api/auth/login", api.Login)
+	r.POST("/api/auth/logout", api.Logout)
+	r.GET("/api/me", api.Me)
 
 	// WebSocket endpoint
 	r.GET("/ws", gin.WrapH(http.HandlerFunc(websocket.HandleWebSocketConnection)))
@@ -53,7 +98,25 @@ func main() {
 	log.Println("  - Static Files: http://localhost:8080/static")
 	log.Println("  - Main Page: http://localhost:8080/")
 
-	if err := http.ListenAndServe(":8080", r); err != nil {
-		log.Fatal("Server failed to start:", err)
+	server := &http.Server{Addr: ":8080", Handler: r}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed to start:", err)
+		}
+	}()
+
+	// Stop cleanly on a signal so the store can write what is still queued
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	log.Println("Shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown failed: %v", err)
 	}
+
+	// WebSocket connections and room timers outlive the HTTP server, so stop
+	// the rooms before the deferred Close shuts the store
+	room.CloseAll()
 }
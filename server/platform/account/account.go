@@ -0,0 +1,125 @@
+package account
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gaming-platform/config"
+	"gaming-platform/platform/auth"
+	"gaming-platform/platform/store"
+	"gaming-platform/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Login errors
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrNotLoggedIn        = errors.New("not logged in")
+)
+
+// loginSession is a logged-in user's session
+type loginSession struct {
+	userID    string
+	expiresAt time.Time
+}
+
+// Global account state
+var (
+	users         UserStore = store.NewMemoryStore()
+	sessions                = make(map[string]loginSession)
+	sessionsMutex           = sync.Mutex{}
+)
+
+// SetUserStore replaces the store used for registered users
+func SetUserStore(store UserStore) {
+	users = store
+}
+
+// Register creates a user with a salted password hash.
+// A random avatar is picked when none is given.
+func Register(email, password, nickname, avatar string) (*User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	if avatar == "" {
+		avatar = utils.GetRandomAvatar()
+	}
+
+	user := &User{
+		ID:           auth.NewUserID(),
+		Email:        strings.TrimSpace(email),
+		Nickname:     strings.TrimSpace(nickname),
+		Avatar:       avatar,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now(),
+	}
+	if err := users.CreateUser(user); err != nil {
+		return nil, err
+	}
+	log.Printf("[ACCOUNT] Registered user %s (%s)", user.ID, user.Nickname)
+	return user, nil
+}
+
+// Login checks a user's password and starts a login session
+func Login(email, password string) (*User, string, error) {
+	user, err := users.GetUserByEmail(email)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, "", ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, "", ErrInvalidCredentials
+	}
+
+	token := StartSession(user.ID)
+	log.Printf("[ACCOUNT] User %s logged in", user.ID)
+	return user, token, nil
+}
+
+// StartSession starts a login session for a user and returns its token
+func StartSession(userID string) string {
+	token := auth.NewLoginToken()
+
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	sessions[token] = loginSession{
+		userID:    userID,
+		expiresAt: time.Now().Add(config.Get().LoginTTL),
+	}
+	return token
+}
+
+// Logout ends a login session
+func Logout(token string) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	delete(sessions, token)
+}
+
+// GetUser returns a registered user
+func GetUser(id string) (*User, error) {
+	return users.GetUser(id)
+}
+
+// UserForToken returns the user logged in with the given session token
+func UserForToken(token string) (*User, error) {
+	sessionsMutex.Lock()
+	session, exists := sessions[token]
+	if exists && time.Now().After(session.expiresAt) {
+		delete(sessions, token)
+		exists = false
+	}
+	sessionsMutex.Unlock()
+
+	if !exists {
+		return nil, ErrNotLoggedIn
+	}
+	return users.GetUser(session.userID)
+}
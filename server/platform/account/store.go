@@ -0,0 +1,16 @@
+// Package account manages registered users and their login sessions
+package account
+
+import "gaming-platform/platform/store"
+
+// User store errors
+var (
+	ErrUserNotFound = store.ErrUserNotFound
+	ErrEmailTaken   = store.ErrEmailTaken
+)
+
+// User represents a registered user
+type User = store.User
+
+// UserStore persists registered users
+type UserStore = store.UserStore
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"gaming-platform/platform/account"
+	"gaming-platform/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRequest represents the request body for the register API
+type RegisterRequest struct {
+	Name     string `json:"name" binding:"required,max=32"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Avatar   string `json:"avatar"`
+}
+
+// LoginRequest represents the request body for the login API
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// AuthResponse represents the response for the register and login APIs
+type AuthResponse struct {
+	User  *account.User `json:"user"`
+	Token string        `json:"token"`
+}
+
+// Register creates a user account and logs it in
+func Register(c *gin.Context) {
+	var request RegisterRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if request.Avatar != "" && !utils.IsValidAvatar(request.Avatar) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "unknown avatar: " + request.Avatar,
+		})
+		return
+	}
+
+	user, err := account.Register(request.Email, request.Password, request.Name, request.Avatar)
+	if errors.Is(err, account.ErrEmailTaken) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("[API] Failed to register %s: %v", request.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to register",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, AuthResponse{
+		User:  user,
+		Token: account.StartSession(user.ID),
+	})
+}
+
+// Login logs a user in and returns a session token
+func Login(c *gin.Context) {
+	var request LoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	user, token, err := account.Login(request.Email, request.Password)
+	if errors.Is(err, account.ErrInvalidCredentials) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("[API] Failed to log in %s: %v", request.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to log in",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		User:  user,
+		Token: token,
+	})
+}
+
+// Logout ends the caller's login session
+func Logout(c *gin.Context) {
+	if token := bearerToken(c); token != "" {
+		account.Logout(token)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Me returns the logged-in user
+func Me(c *gin.Context) {
+	user, err := account.UserForToken(bearerToken(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Not logged in",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// bearerToken returns the token from the request's Authorization header
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+}
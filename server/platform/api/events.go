@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gaming-platform/config"
+	"gaming-platform/core"
+	"gaming-platform/platform/results"
+	"gaming-platform/platform/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// maxReplaySpeed bounds how much a replay may be sped up
+const maxReplaySpeed = 50
+
+// replayUpgrader upgrades replay connections from any origin, like the game socket
+var replayUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// GetResultEvents returns a recorded game's event log as JSON Lines
+func GetResultEvents(c *gin.Context) {
+	events, ok := loadEvents(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+c.Param("id")+`.jsonl"`)
+	c.Data(http.StatusOK, "application/x-ndjson", events)
+}
+
+// ReplayResult re-sends the messages broadcast during a recorded game over a
+// WebSocket, at the original pace or `speed` times faster. `last` replays
+// only the final seconds of the game. Messages only the players saw are
+// left out, so the replay shows what the host's screen showed.
+func ReplayResult(c *gin.Context) {
+	speed := 1.0
+	if value := c.Query("speed"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > maxReplaySpeed {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "speed must be a number above 0 and at most " + strconv.Itoa(maxReplaySpeed),
+			})
+			return
+		}
+		speed = parsed
+	}
+	last := 0
+	if value := c.Query("last"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "last must be a number of seconds",
+			})
+			return
+		}
+		last = parsed
+	}
+
+	encoded, ok := loadEvents(c)
+	if !ok {
+		return
+	}
+	events, err := decodeEvents(encoded)
+	if err != nil {
+		log.Printf("[API] Failed to decode the event log of game %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to read event log",
+		})
+		return
+	}
+
+	conn, err := replayUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("[API] Replay upgrade error from %s: %v", c.Request.RemoteAddr, err)
+		return
+	}
+	defer conn.Close()
+
+	// Reading tells when the viewer goes away; nothing they send is used
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	from := int64(0)
+	if last > 0 && len(events) > 0 {
+		from = events[len(events)-1].At - int64(last)*1000
+	}
+	log.Printf("[API] Replaying game %s to %s at %gx from %dms", c.Param("id"), c.Request.RemoteAddr, speed, from)
+
+	send := func(message []byte) bool {
+		conn.SetWriteDeadline(time.Now().Add(config.Get().WriteWait))
+		return conn.WriteMessage(websocket.TextMessage, message) == nil
+	}
+	started, _ := json.Marshal(map[string]interface{}{
+		"type":     "replayStarted",
+		"resultId": c.Param("id"),
+		"speed":    speed,
+		"from":     from,
+	})
+	if !send(started) {
+		return
+	}
+
+	previous := from
+	for _, event := range events {
+		if event.Kind != core.EventBroadcast || event.To == core.AudiencePlayers || event.At < from {
+			continue
+		}
+
+		wait := time.Duration(float64(event.At-previous) / speed * float64(time.Millisecond))
+		select {
+		case <-time.After(wait):
+		case <-closed:
+			return
+		}
+		previous = event.At
+
+		if !send(event.Message) {
+			return
+		}
+	}
+
+	// The closing message carries the final standings from the log's end event
+	endMessage := map[string]interface{}{
+		"type":     "replayEnded",
+		"resultId": c.Param("id"),
+	}
+	if len(events) > 0 && events[len(events)-1].Kind == core.EventEnd {
+		if data, ok := events[len(events)-1].Data.(map[string]interface{}); ok {
+			endMessage["players"] = data["players"]
+		}
+	}
+	ended, _ := json.Marshal(endMessage)
+	send(ended)
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(config.Get().WriteWait))
+}
+
+// loadEvents reads the event log of the game named in the request,
+// answering the request itself when there is none
+func loadEvents(c *gin.Context) ([]byte, bool) {
+	events, err := results.Events(c.Param("id"))
+	if errors.Is(err, store.ErrEventsNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Event log not found",
+		})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("[API] Failed to get the event log of game %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get event log",
+		})
+		return nil, false
+	}
+	return events, true
+}
+
+// decodeEvents parses a JSON Lines event log
+func decodeEvents(encoded []byte) ([]core.Event, error) {
+	var events []core.Event
+	scanner := bufio.NewScanner(bytes.NewReader(encoded))
+	scanner.Buffer(nil, len(encoded)+1)
+	for scanner.Scan() {
+		var event core.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
@@ -2,27 +2,204 @@
 package api
 
 import (
-	"fmt"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 
+	"gaming-platform/config"
 	"gaming-platform/core"
+	"gaming-platform/core/message"
+	"gaming-platform/platform/account"
+	"gaming-platform/platform/auth"
 	"gaming-platform/platform/room"
+	"gaming-platform/platform/store"
+	"gaming-platform/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// HealthCheck handles health check requests
+// dataStore is the store the health check reports on
+var dataStore store.Store = store.NewMemoryStore()
+
+// SetStore replaces the store the health check reports on
+func SetStore(s store.Store) {
+	dataStore = s
+}
+
+// HealthCheck handles health check requests. It fails while the store cannot
+// write changes, which stay queued until it can.
 func HealthCheck(c *gin.Context) {
+	if err := dataStore.Err(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "degraded",
+			"message": "Store writes are failing and are being retried",
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "ok",
 		"message": "Gaming platform server is running",
 	})
 }
 
+// CreateRoom creates a room for a game type and returns its join code and host secret
+func CreateRoom(c *gin.Context) {
+	var request core.CreateRoomRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Reject settings the game would refuse when starting
+	if err := message.ValidateSettings(request.GameType, request.Settings); err != nil {
+		response := gin.H{
+			"error": err.Error(),
+		}
+		var clientErr *core.Error
+		if errors.As(err, &clientErr) {
+			response["code"] = clientErr.Code
+			response["fields"] = clientErr.Fields
+		}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	teams := room.NormalizeTeams(request.Teams)
+	if request.Teams != nil && teams == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Teams need at least two distinct names",
+		})
+		return
+	}
+
+	gameRoom := room.CreateRoom(room.Options{
+		GameType:    request.GameType,
+		Settings:    request.Settings,
+		Capacity:    request.Capacity,
+		Teams:       teams,
+		IdleTimeout: config.Get().RoomIdleTimeout,
+		Seed:        request.Seed,
+	})
+	log.Printf("[API] Created room %s for %s", gameRoom.ID, request.GameType)
+
+	c.JSON(http.StatusCreated, core.CreateRoomResponse{
+		RoomID:     gameRoom.ID,
+		JoinCode:   gameRoom.ID,
+		HostSecret: gameRoom.HostSecret,
+		GameType:   gameRoom.GameType,
+		Settings:   gameRoom.Settings,
+		Capacity:   gameRoom.Capacity,
+		Teams:      teams,
+		Seed:       gameRoom.Seed,
+	})
+}
+
+// JoinRoom issues the signed access token a client presents to connect to a room.
+// The token carries the player's ID, user, nickname and role in the room.
+func JoinRoom(c *gin.Context) {
+	roomID := roomIDParam(c)
+
+	var request core.JoinRoomRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	gameRoom, exists := room.GetRoom(roomID)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Room not found",
+		})
+		return
+	}
+
+	claims := auth.AccessClaims{
+		PlayerID: auth.NewPlayerID(),
+		RoomID:   roomID,
+		Role:     core.RolePlayer,
+		Nickname: strings.TrimSpace(request.Nickname),
+	}
+	if claims.Nickname == "" {
+		claims.Nickname = "Anonymous"
+	}
+
+	if request.Spectator {
+		claims.Role = core.RoleSpectator
+	}
+
+	// Only holders of the room's host secret become host
+	if request.HostSecret != "" {
+		if !auth.CheckSecret(gameRoom.HostSecret, request.HostSecret) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Invalid host secret",
+			})
+			return
+		}
+		claims.Role = core.RoleHost
+	}
+
+	// A valid session token keeps the player's previous identity
+	if request.SessionToken != "" {
+		session, err := auth.VerifySessionToken(request.SessionToken, roomID)
+		if err != nil {
+			log.Printf("[API] Ignoring session token for room %s: %v", roomID, err)
+		} else {
+			claims.PlayerID = session.PlayerID
+		}
+	}
+
+	// Logged-in users play under their account's nickname and avatar
+	if token := bearerToken(c); token != "" {
+		user, err := account.UserForToken(token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Not logged in",
+			})
+			return
+		}
+		claims.UserID = user.ID
+		claims.Nickname = user.Nickname
+		claims.Avatar = user.Avatar
+	}
+
+	// Guests get an avatar from the room's random source
+	if claims.Avatar == "" {
+		if !gameRoom.Do(func() { claims.Avatar = utils.PickAvatar(gameRoom.Rand) }) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Room not found",
+			})
+			return
+		}
+	}
+
+	accessToken, err := auth.IssueAccessToken(claims)
+	if err != nil {
+		log.Printf("[API] Failed to issue access token for room %s: %v", roomID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to join room",
+		})
+		return
+	}
+
+	log.Printf("[API] %s joins room %s as %s", claims.Nickname, roomID, claims.Role)
+	c.JSON(http.StatusOK, core.JoinRoomResponse{
+		AccessToken: accessToken,
+		RoomID:      roomID,
+		PlayerID:    claims.PlayerID,
+		Role:        claims.Role,
+	})
+}
+
 // GetPlayerList returns the player list for a specific room
 func GetPlayerList(c *gin.Context) {
-	roomID := c.Param("roomId")
+	roomID := roomIDParam(c)
 	log.Printf("[API] Getting player list for room %s", roomID)
 
 	gameRoom, exists := room.GetRoom(roomID)
@@ -35,27 +212,18 @@ func GetPlayerList(c *gin.Context) {
 
 	players := []core.Player{}
 
-	// Add host if exists
-	if gameRoom.HostClient != nil {
-		players = append(players, core.Player{
-			Nickname: gameRoom.HostClient.Nickname,
-			ID:       fmt.Sprintf("%p", gameRoom.HostClient.Conn),
-			IsHost:   true,
-			Score:    gameRoom.HostClient.Score,
-			Avatar:   gameRoom.HostClient.Avatar,
-		})
-	}
+	// Read room state on the room's event loop
+	gameRoom.Do(func() {
+		// Add host if exists
+		if gameRoom.HostClient != nil {
+			players = append(players, gameRoom.HostClient.Player())
+		}
 
-	// Add player clients
-	for client := range gameRoom.PlayerClients {
-		players = append(players, core.Player{
-			Nickname: client.Nickname,
-			ID:       fmt.Sprintf("%p", client.Conn),
-			IsHost:   false,
-			Score:    client.Score,
-			Avatar:   client.Avatar,
-		})
-	}
+		// Add player clients
+		for client := range gameRoom.PlayerClients {
+			players = append(players, client.Player())
+		}
+	})
 
 	log.Printf("[API] Returning %d players for room %s", len(players), roomID)
 	c.JSON(http.StatusOK, core.PlayerListResponse{
@@ -65,7 +233,7 @@ func GetPlayerList(c *gin.Context) {
 
 // GetRoomInfo returns information about a specific room
 func GetRoomInfo(c *gin.Context) {
-	roomID := c.Param("roomId")
+	roomID := roomIDParam(c)
 	log.Printf("[API] Getting room info for room %s", roomID)
 
 	gameRoom, exists := room.GetRoom(roomID)
@@ -76,26 +244,37 @@ func GetRoomInfo(c *gin.Context) {
 		return
 	}
 
-	roomInfo := core.RoomInfoResponse{
-		RoomID:            gameRoom.ID,
-		TotalPlayers:      gameRoom.TotalPlayers,
-		WaitingForPlayers: gameRoom.WaitingForPlayers,
-		GameStarted:       gameRoom.GameStarted,
-		GameEnded:         gameRoom.GameEnded,
-	}
+	// Read room state on the room's event loop
+	var roomInfo core.RoomInfoResponse
+	gameRoom.Do(func() {
+		roomInfo = core.RoomInfoResponse{
+			RoomID:            gameRoom.ID,
+			GameType:          gameRoom.GameType,
+			Capacity:          gameRoom.Capacity,
+			TotalPlayers:      gameRoom.TotalPlayers,
+			Spectators:        len(gameRoom.SpectatorClients),
+			WaitingForPlayers: gameRoom.WaitingForPlayers,
+			GameStarted:       gameRoom.GameStarted,
+			GameEnded:         gameRoom.GameEnded,
+		}
+	})
 
 	log.Printf("[API] Returning room info for room %s: %+v", roomID, roomInfo)
 	c.JSON(http.StatusOK, roomInfo)
 }
 
-// GetRoomList returns a list of all active rooms
+// GetRoomList returns the number of active rooms. Join codes are the only
+// thing needed to join a room, so they are not listed.
 func GetRoomList(c *gin.Context) {
-	log.Printf("[API] Getting room list")
-
-	roomList := room.GetRoomList()
+	log.Printf("[API] Getting room count")
 
 	c.JSON(http.StatusOK, gin.H{
-		"rooms": roomList,
-		"count": len(roomList),
+		"count": room.GetRoomCount(),
 	})
 }
+
+// roomIDParam returns the join code in the request path. Codes are
+// case-insensitive and stored upper-case.
+func roomIDParam(c *gin.Context) string {
+	return strings.ToUpper(strings.TrimSpace(c.Param("roomId")))
+}
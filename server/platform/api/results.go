@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"gaming-platform/platform/results"
+	"gaming-platform/platform/room"
+	"gaming-platform/platform/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoomResults returns the games played in a room, oldest first.
+// Results stay available after the room has closed.
+func GetRoomResults(c *gin.Context) {
+	roomID := roomIDParam(c)
+	if !room.Exists(roomID) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Room not found",
+		})
+		return
+	}
+
+	roomResults, err := results.ForRoom(roomID)
+	if err != nil {
+		log.Printf("[API] Failed to list results for room %s: %v", roomID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list results",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": roomResults,
+		"count":   len(roomResults),
+	})
+}
+
+// GetResult returns a single recorded game
+func GetResult(c *gin.Context) {
+	result, err := results.Get(c.Param("id"))
+	if errors.Is(err, store.ErrResultNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Result not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("[API] Failed to get result %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get result",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"gaming-platform/platform/account"
+	"gaming-platform/platform/stats"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPlayerStats returns a registered player's lifetime statistics per game type
+func GetPlayerStats(c *gin.Context) {
+	userID := c.Param("id")
+
+	user, err := account.GetUser(userID)
+	if errors.Is(err, account.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Player not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("[API] Failed to get user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get player",
+		})
+		return
+	}
+
+	playerStats, err := stats.ForUser(userID)
+	if err != nil {
+		log.Printf("[API] Failed to get stats for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get player stats",
+		})
+		return
+	}
+
+	// Only the public profile is shown; the email stays private
+	c.JSON(http.StatusOK, gin.H{
+		"player": gin.H{
+			"id":       user.ID,
+			"nickname": user.Nickname,
+			"avatar":   user.Avatar,
+		},
+		"stats": playerStats,
+	})
+}
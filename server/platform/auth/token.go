@@ -0,0 +1,184 @@
+// Package auth issues and verifies the signed tokens used by the gaming platform
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"gaming-platform/config"
+	"gaming-platform/core"
+)
+
+// Token verification errors
+var (
+	ErrMalformedToken = errors.New("malformed token")
+	ErrBadSignature   = errors.New("invalid token signature")
+	ErrTokenExpired   = errors.New("token expired")
+	ErrWrongRoom      = errors.New("token issued for another room")
+	ErrWrongKind      = errors.New("wrong kind of token")
+)
+
+// Token kinds, so one kind of token cannot be presented as another
+const (
+	kindSession = "session"
+	kindAccess  = "access"
+)
+
+// SessionClaims identify a player's seat in a room
+type SessionClaims struct {
+	Kind      string `json:"typ"`
+	PlayerID  string `json:"pid"`
+	RoomID    string `json:"rid"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// AccessClaims authorize a WebSocket connection to a room with a role
+type AccessClaims struct {
+	Kind      string    `json:"typ"`
+	PlayerID  string    `json:"pid"`
+	UserID    string    `json:"uid,omitempty"`
+	RoomID    string    `json:"rid"`
+	Role      core.Role `json:"role"`
+	Nickname  string    `json:"nick"`
+	Avatar    string    `json:"av"`
+	ExpiresAt int64     `json:"exp"`
+}
+
+// NewPlayerID generates a new random player ID
+func NewPlayerID() string {
+	return "p_" + randomHex(8)
+}
+
+// NewUserID generates a new random user ID
+func NewUserID() string {
+	return "u_" + randomHex(8)
+}
+
+// NewResultID generates a new random game result ID
+func NewResultID() string {
+	return "r_" + randomHex(8)
+}
+
+// NewLoginToken generates a new random login session token
+func NewLoginToken() string {
+	return randomHex(32)
+}
+
+// NewHostSecret generates a new random host secret for a room
+func NewHostSecret() string {
+	return randomHex(16)
+}
+
+// CheckSecret compares a presented secret with the expected one in constant time
+func CheckSecret(expected, presented string) bool {
+	if expected == "" {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(presented))
+}
+
+// IssueSessionToken signs a session token that lets a player resume their seat
+func IssueSessionToken(playerID, roomID string) (string, error) {
+	return Sign(SessionClaims{
+		Kind:      kindSession,
+		PlayerID:  playerID,
+		RoomID:    roomID,
+		ExpiresAt: time.Now().Add(config.Get().SessionTTL).Unix(),
+	})
+}
+
+// VerifySessionToken checks a session token's signature, expiry and room
+func VerifySessionToken(token, roomID string) (*SessionClaims, error) {
+	var claims SessionClaims
+	if err := Verify(token, &claims); err != nil {
+		return nil, err
+	}
+	if claims.Kind != kindSession {
+		return nil, ErrWrongKind
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	if claims.RoomID != roomID {
+		return nil, ErrWrongRoom
+	}
+	return &claims, nil
+}
+
+// IssueAccessToken signs an access token that admits a player to a room with a role
+func IssueAccessToken(claims AccessClaims) (string, error) {
+	claims.Kind = kindAccess
+	claims.ExpiresAt = time.Now().Add(config.Get().SessionTTL).Unix()
+	return Sign(claims)
+}
+
+// VerifyAccessToken checks an access token's signature, kind, expiry and role
+func VerifyAccessToken(token string) (*AccessClaims, error) {
+	var claims AccessClaims
+	if err := Verify(token, &claims); err != nil {
+		return nil, err
+	}
+	if claims.Kind != kindAccess || claims.PlayerID == "" || claims.RoomID == "" {
+		return nil, ErrWrongKind
+	}
+	if !claims.Role.Valid() {
+		return nil, ErrMalformedToken
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+// Sign serializes claims and appends an HMAC-SHA256 signature
+func Sign(claims interface{}) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signature(encoded), nil
+}
+
+// Verify checks a token's signature and decodes its claims
+func Verify(token string, claims interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return ErrMalformedToken
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(signature(parts[0]))) {
+		return ErrBadSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return ErrMalformedToken
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return ErrMalformedToken
+	}
+	return nil
+}
+
+// signature computes the base64url HMAC of an encoded payload
+func signature(encoded string) string {
+	mac := hmac.New(sha256.New, []byte(config.Get().TokenSecret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
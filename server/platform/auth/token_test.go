@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gaming-platform/core"
+)
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	token, err := Sign(SessionClaims{Kind: kindSession, PlayerID: "p_1", RoomID: "ABC234", ExpiresAt: 42})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	var claims SessionClaims
+	if err := Verify(token, &claims); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.PlayerID != "p_1" || claims.RoomID != "ABC234" || claims.ExpiresAt != 42 {
+		t.Fatalf("claims = %+v", claims)
+	}
+}
+
+func TestVerifyRejectsTamperedTokens(t *testing.T) {
+	token, err := Sign(SessionClaims{Kind: kindSession, PlayerID: "p_1", RoomID: "ABC234"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	forged, err := Sign(SessionClaims{Kind: kindSession, PlayerID: "p_2", RoomID: "ABC234"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"swapped payload", forgedPayload + "." + signature, ErrBadSignature},
+		{"no signature", payload, ErrMalformedToken},
+		{"extra part", token + ".x", ErrMalformedToken},
+		{"empty", "", ErrMalformedToken},
+	}
+	for _, test := range tests {
+		var claims SessionClaims
+		if err := Verify(test.token, &claims); !errors.Is(err, test.want) {
+			t.Errorf("%s: Verify = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestVerifySessionToken(t *testing.T) {
+	token, err := IssueSessionToken("p_1", "ABC234")
+	if err != nil {
+		t.Fatalf("IssueSessionToken: %v", err)
+	}
+	claims, err := VerifySessionToken(token, "ABC234")
+	if err != nil {
+		t.Fatalf("VerifySessionToken: %v", err)
+	}
+	if claims.PlayerID != "p_1" {
+		t.Fatalf("PlayerID = %q, want p_1", claims.PlayerID)
+	}
+
+	if _, err := VerifySessionToken(token, "XYZ789"); !errors.Is(err, ErrWrongRoom) {
+		t.Errorf("other room: err = %v, want ErrWrongRoom", err)
+	}
+
+	expired, _ := Sign(SessionClaims{Kind: kindSession, PlayerID: "p_1", RoomID: "ABC234", ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	if _, err := VerifySessionToken(expired, "ABC234"); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expired: err = %v, want ErrTokenExpired", err)
+	}
+
+	wrongKind, _ := Sign(SessionClaims{Kind: kindAccess, PlayerID: "p_1", RoomID: "ABC234", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	if _, err := VerifySessionToken(wrongKind, "ABC234"); !errors.Is(err, ErrWrongKind) {
+		t.Errorf("wrong kind: err = %v, want ErrWrongKind", err)
+	}
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	token, err := IssueAccessToken(AccessClaims{PlayerID: "p_1", RoomID: "ABC234", Role: core.RoleHost, Nickname: "Ann"})
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+	claims, err := VerifyAccessToken(token)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	if claims.PlayerID != "p_1" || claims.RoomID != "ABC234" || claims.Role != core.RoleHost || claims.Nickname != "Ann" {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	// A session token cannot be used to connect
+	session, _ := IssueSessionToken("p_1", "ABC234")
+	if _, err := VerifyAccessToken(session); !errors.Is(err, ErrWrongKind) {
+		t.Errorf("session token: err = %v, want ErrWrongKind", err)
+	}
+
+	valid := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name   string
+		claims AccessClaims
+		want   error
+	}{
+		{"expired", AccessClaims{Kind: kindAccess, PlayerID: "p_1", RoomID: "ABC234", Role: core.RolePlayer, ExpiresAt: time.Now().Add(-time.Minute).Unix()}, ErrTokenExpired},
+		{"unknown role", AccessClaims{Kind: kindAccess, PlayerID: "p_1", RoomID: "ABC234", Role: "admin", ExpiresAt: valid}, ErrMalformedToken},
+		{"no room", AccessClaims{Kind: kindAccess, PlayerID: "p_1", Role: core.RolePlayer, ExpiresAt: valid}, ErrWrongKind},
+		{"no player", AccessClaims{Kind: kindAccess, RoomID: "ABC234", Role: core.RolePlayer, ExpiresAt: valid}, ErrWrongKind},
+	}
+	for _, test := range tests {
+		token, _ := Sign(test.claims)
+		if _, err := VerifyAccessToken(token); !errors.Is(err, test.want) {
+			t.Errorf("%s: err = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package results records finished games with their event logs and serves their history
+package results
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"gaming-platform/core"
+	"gaming-platform/platform/auth"
+	"gaming-platform/platform/stats"
+	"gaming-platform/platform/store"
+)
+
+// Store keeps recorded games and their event logs
+type Store interface {
+	store.ResultStore
+	store.EventStore
+}
+
+// results holds the recorded games
+var results Store = store.NewMemoryStore()
+
+// Listener is told about every finished game after it has been recorded
+type Listener func(gameRoom *core.Room, result *store.GameResult)
+
+// listeners are registered during package initialization
+var listeners []Listener
+
+// OnRecorded registers a listener for finished games. It must be called from an init function.
+func OnRecorded(listener Listener) {
+	listeners = append(listeners, listener)
+}
+
+// SetStore replaces the store finished games are recorded in
+func SetStore(rs Store) {
+	results = rs
+}
+
+// Record stores a finished game with its seed and its players' final ranks and scores,
+// adds it to the registered players' statistics, saves the room's event log
+// for the game, tells the listeners and returns the result ID. Players still in the room get their user ID, avatar
+// and team filled in. It must be called on the room's event loop.
+func Record(gameRoom *core.Room, gameType string, settings interface{}, seed int64, startedAt time.Time, players []store.PlayerResult) string {
+	encodedSettings, err := json.Marshal(settings)
+	if err != nil {
+		log.Printf("[RESULTS] Failed to encode %s settings for room %s: %v", gameType, gameRoom.ID, err)
+	}
+
+	for i := range players {
+		for client := range gameRoom.PlayerClients {
+			if client.ID == players[i].PlayerID {
+				players[i].UserID = client.UserID
+				players[i].Avatar = client.Avatar
+				players[i].Team = client.Team
+				break
+			}
+		}
+	}
+
+	result := &store.GameResult{
+		ID:        auth.NewResultID(),
+		RoomID:    gameRoom.ID,
+		GameType:  gameType,
+		Settings:  encodedSettings,
+		Seed:      seed,
+		StartedAt: startedAt,
+		EndedAt:   time.Now(),
+		Players:   players,
+	}
+	if err := results.SaveResult(result); err != nil {
+		log.Printf("[RESULTS] Failed to record %s game for room %s: %v", gameType, gameRoom.ID, err)
+		result.ID = ""
+	} else {
+		log.Printf("[RESULTS] Recorded %s game %s for room %s with %d players", gameType, result.ID, gameRoom.ID, len(players))
+		stats.Record(result)
+		saveEvents(gameRoom, result)
+	}
+	gameRoom.EventLog = nil
+
+	// Listeners hear about the game even if it could not be stored
+	for _, listener := range listeners {
+		listener(gameRoom, result)
+	}
+	return result.ID
+}
+
+// Get returns a recorded game
+func Get(id string) (*store.GameResult, error) {
+	return results.GetResult(id)
+}
+
+// ForRoom returns the games recorded for a room, oldest first
+func ForRoom(roomID string) ([]*store.GameResult, error) {
+	return results.ListResults(roomID)
+}
+
+// Events returns the JSON Lines event log of a recorded game
+func Events(id string) ([]byte, error) {
+	return results.GetEvents(id)
+}
+
+// saveEvents closes the game's event log with its result and stores it
+func saveEvents(gameRoom *core.Room, result *store.GameResult) {
+	if gameRoom.EventLog == nil {
+		return
+	}
+
+	gameRoom.LogEvent(core.Event{
+		Kind: core.EventEnd,
+		Data: map[string]interface{}{
+			"resultId": result.ID,
+			"players":  result.Players,
+		},
+	})
+	events, err := gameRoom.EventLog.JSONLines()
+	if err == nil {
+		err = results.SaveEvents(result.ID, events)
+	}
+	if err != nil {
+		log.Printf("[RESULTS] Failed to save the event log of game %s: %v", result.ID, err)
+		return
+	}
+	log.Printf("[RESULTS] Saved %d events for game %s", len(gameRoom.EventLog.Events()), result.ID)
+}
@@ -3,13 +3,15 @@ package room
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"math/rand"
 	"sync"
 	"time"
 
 	"gaming-platform/core"
-
-	"github.com/gorilla/websocket"
+	"gaming-platform/platform/auth"
+	"gaming-platform/platform/store"
 )
 
 // Global rooms storage
@@ -18,45 +20,110 @@ var (
 	roomsMutex = sync.RWMutex{}
 )
 
-// CreateRoom creates a new room with the given ID
-func CreateRoom(roomID string) *core.Room {
+// roomStore records created and closed rooms
+var roomStore store.RoomStore = store.NewMemoryStore()
+
+// Join errors
+var (
+	ErrRoomClosed = errors.New("room closed")
+	ErrRoomFull   = errors.New("room is full")
+)
+
+// joinCodeAlphabet leaves out characters that are easy to confuse when read aloud
+const joinCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// joinCodeLength is the number of characters in a room's join code
+const joinCodeLength = 6
+
+// SetStore replaces the store rooms are recorded in
+func SetStore(rs store.RoomStore) {
+	roomStore = rs
+}
+
+// Options configures a room created through the API
+type Options struct {
+	GameType    string
+	Settings    json.RawMessage
+	Capacity    int                // Maximum number of players, zero for unlimited
+	Teams       *core.TeamSettings // Team play configuration, nil for individual play
+	IdleTimeout time.Duration      // Close the room if nobody has joined by then; zero keeps it open
+	Seed        int64              // Seeds the room's random draws; zero picks one from the clock
+}
+
+// CreateRoom creates a room under a new join code, generates its host secret
+// and starts its event loop
+func CreateRoom(options Options) *core.Room {
 	roomsMutex.Lock()
-	defer roomsMutex.Unlock()
-
-	room := &core.Room{
-		ID:                roomID,
-		HostClient:        nil,
-		PlayerClients:     make(map[*core.Client]bool), // Only non-host players
-		AllClients:        make(map[*core.Client]bool), // All clients for backward compatibility
-		GameTime:          0,
-		TotalPlayers:      0,
-		PlayersReady:      make(map[string]bool),
-		WaitingForPlayers: true,
-		GameStarted:       false,
-		GameEnded:         false,
-		Timer:             nil,
-		ReconnectionChan:  make(chan core.ReconnectionRequest, 10),
-		StopChan:          make(chan bool, 1),
-	}
-
-	rooms[roomID] = room
-	log.Printf("[ROOM %s] Room created.", roomID)
-
-	// Start the room's reconnection handler goroutine
-	go handleReconnections(room)
+
+	// Codes of closed rooms stay reserved so their results remain addressable
+	code := newJoinCode()
+	for rooms[code] != nil || roomRecorded(code) {
+		code = newJoinCode()
+	}
+
+	room := core.NewRoom(code)
+	room.GameType = options.GameType
+	room.Settings = options.Settings
+	room.Capacity = options.Capacity
+	room.Teams = NormalizeTeams(options.Teams)
+	room.HostSecret = auth.NewHostSecret()
+	room.Seed = options.Seed
+	if room.Seed == 0 {
+		room.Seed = time.Now().UnixNano()
+	}
+	room.Rand = rand.New(rand.NewSource(room.Seed))
+
+	// Record the room on its own event loop, off roomsMutex. Posting before
+	// the room is listed makes the record its first event, ahead of any close.
+	record := &store.Room{
+		ID:        code,
+		GameType:  options.GameType,
+		Settings:  options.Settings,
+		Capacity:  options.Capacity,
+		CreatedAt: time.Now(),
+	}
+	room.Post(func() {
+		if err := roomStore.SaveRoom(record); err != nil {
+			log.Printf("[ROOM %s] Failed to record room: %v", code, err)
+		}
+	})
+
+	rooms[code] = room
+	roomsMutex.Unlock()
+	log.Printf("[ROOM %s] Room created for %s (capacity: %d, seed: %d)", code, options.GameType, options.Capacity, room.Seed)
+
+	if options.IdleTimeout > 0 {
+		room.After(options.IdleTimeout, func() {
+			if len(room.AllClients) == 0 {
+				log.Printf("[ROOM %s] Nobody joined within %s, closing", room.ID, options.IdleTimeout)
+				closeRoom(room)
+			}
+		})
+	}
 	return room
 }
 
-// GetOrCreateRoom gets a room by ID, creates if it doesn't exist
-func GetOrCreateRoom(roomID string) *core.Room {
-	roomsMutex.RLock()
-	room, exists := rooms[roomID]
-	roomsMutex.RUnlock()
+// newJoinCode generates a short random join code
+func newJoinCode() string {
+	code := make([]byte, joinCodeLength)
+	for i := range code {
+		code[i] = joinCodeAlphabet[rand.Intn(len(joinCodeAlphabet))]
+	}
+	return string(code)
+}
+
+// roomRecorded reports whether the store already holds a room with the code
+func roomRecorded(code string) bool {
+	_, err := roomStore.GetRoom(code)
+	return err == nil
+}
 
-	if !exists {
-		return CreateRoom(roomID)
+// Exists reports whether a room was ever created with the ID, even if it has closed since
+func Exists(roomID string) bool {
+	if _, exists := GetRoom(roomID); exists {
+		return true
 	}
-	return room
+	return roomRecorded(roomID)
 }
 
 // GetRoom gets a room by ID
@@ -67,68 +134,65 @@ func GetRoom(roomID string) (*core.Room, bool) {
 	return room, exists
 }
 
-// RegisterClient adds a client to a room with separated storage
-func RegisterClient(room *core.Room, client *core.Client) {
+// RegisterClient adds a client to a room on the room's event loop.
+// A client carrying the player ID of an existing seat takes that seat over.
+// It returns ErrRoomClosed if the room was closed before the client could
+// join and ErrRoomFull if the room has no free player seat.
+func RegisterClient(room *core.Room, client *core.Client) error {
+	var err error
+	if !room.Do(func() {
+		err = registerClient(room, client)
+	}) {
+		return ErrRoomClosed
+	}
+	return err
+}
+
+// registerClient adds a client to a room with separated storage
+func registerClient(room *core.Room, client *core.Client) error {
 	log.Printf("[ROOM %s] Registering client %s (IsHost: %t)", room.ID, client.Nickname, client.IsHost)
 
-	// Check if this is a reconnection attempt
-	if room.GameStarted {
-		// Try to reconnect
-		reconnectReq := core.ReconnectionRequest{
-			Client:   client,
-			Response: make(chan bool, 1),
-		}
+	// A client carrying the ID of an existing seat resumes that seat
+	if reconnectClient(room, client) {
+		log.Printf("[ROOM %s] Client %s successfully reconnected", room.ID, client.Nickname)
+		return nil
+	}
 
-		select {
-		case room.ReconnectionChan <- reconnectReq:
-			// Wait for response
-			select {
-			case success := <-reconnectReq.Response:
-				if success {
-					log.Printf("[ROOM %s] Client %s successfully reconnected", room.ID, client.Nickname)
-					return
-				} else {
-					log.Printf("[ROOM %s] Client %s reconnection failed", room.ID, client.Nickname)
-				}
-			case <-time.After(5 * time.Second):
-				log.Printf("[ROOM %s] Client %s reconnection timeout", room.ID, client.Nickname)
-			}
-		default:
-			log.Printf("[ROOM %s] Reconnection channel full for client %s", room.ID, client.Nickname)
-		}
+	if !client.IsHost && !client.IsSpectator && room.Capacity > 0 && len(room.PlayerClients) >= room.Capacity {
+		log.Printf("[ROOM %s] Room is full (%d players), refusing %s", room.ID, room.Capacity, client.Nickname)
+		return ErrRoomFull
 	}
 
 	// Add client to appropriate storage
-	if client.IsHost || room.HostClient == nil {
+	if client.IsHost {
 		// Set as host
 		room.HostClient = client
 		client.IsHost = true
 		log.Printf("[ROOM %s] %s is now the host", room.ID, client.Nickname)
+	} else if client.IsSpectator {
+		// Spectators watch without a seat among the players
+		room.SpectatorClients[client] = true
+		log.Printf("[ROOM %s] %s is spectating", room.ID, client.Nickname)
 	} else {
 		// Add to player storage
 		room.PlayerClients[client] = true
+		assignTeam(room, client)
 		log.Printf("[ROOM %s] %s added to player storage", room.ID, client.Nickname)
 	}
 
 	// Add to all clients for backward compatibility
 	room.AllClients[client] = true
-	room.TotalPlayers = len(room.AllClients)
+	room.TotalPlayers = countPlayers(room)
 
-	log.Printf("[ROOM %s] Client %s registered. Total players: %d, Host: %v, Players: %d", 
-		room.ID, client.Nickname, room.TotalPlayers, room.HostClient != nil, len(room.PlayerClients))
+	log.Printf("[ROOM %s] Client %s registered. Total players: %d, Host: %v, Players: %d, Spectators: %d",
+		room.ID, client.Nickname, room.TotalPlayers, room.HostClient != nil, len(room.PlayerClients), len(room.SpectatorClients))
 
-	// Only broadcast player joined notification for non-host players
-	if !client.IsHost {
+	// Only broadcast player joined notification for players
+	if !client.IsHost && !client.IsSpectator {
 		playerJoinedMsg := map[string]interface{}{
 			"type": "playerJoined",
 			"data": map[string]interface{}{
-				"player": core.Player{
-					Nickname: client.Nickname,
-					ID:       client.Nickname,
-					IsHost:   false,
-					Score:    client.Score,
-					Avatar:   client.Avatar,
-				},
+				"player":       client.Player(),
 				"totalPlayers": len(room.PlayerClients), // 只計算玩家
 			},
 		}
@@ -136,29 +200,79 @@ func RegisterClient(room *core.Room, client *core.Client) {
 		BroadcastToAllClients(room, playerJoinedMsg)
 	}
 
+	// Let the active game catch up the new client
+	if room.Game != nil {
+		room.Game.OnPlayerJoin(client)
+	}
+
 	// Always broadcast updated player list
 	broadcastPlayerListUpdate(room)
+	return nil
 }
 
-// UnregisterClient removes a client from a room with separated storage
-func UnregisterClient(room *core.Room, client *core.Client) {
-	log.Printf("[ROOM %s] Unregistering client %s (IsHost: %t)", room.ID, client.Nickname, client.IsHost)
+// DisconnectClient handles a dropped connection on the room's event loop.
+// The player is marked away and keeps their seat, score and host role for the
+// grace period; they are removed only if they have not reconnected by then.
+// A zero grace period removes the player at once.
+func DisconnectClient(room *core.Room, client *core.Client, reason core.DisconnectReason, grace time.Duration) {
+	room.Do(func() {
+		// The seat was already taken over by a newer connection
+		if !room.AllClients[client] {
+			return
+		}
+
+		if grace <= 0 {
+			unregisterClient(room, client, reason)
+			return
+		}
+
+		log.Printf("[ROOM %s] Client %s is away (reason: %s), holding seat for %s", room.ID, client.Nickname, reason, grace)
+		client.Away = true
+		client.StopGrace = room.After(grace, func() {
+			client.StopGrace = nil
+			if room.AllClients[client] && client.Away {
+				log.Printf("[ROOM %s] Grace period for %s expired", room.ID, client.Nickname)
+				unregisterClient(room, client, core.DisconnectGraceExpired)
+			}
+		})
+
+		BroadcastToAllClients(room, map[string]interface{}{
+			"type": "playerAway",
+			"data": map[string]interface{}{
+				"player":       client.Player(),
+				"reason":       reason,
+				"graceSeconds": int(grace.Seconds()),
+			},
+		})
+		broadcastPlayerListUpdate(room)
+	})
+}
+
+// UnregisterClient removes a client from a room on the room's event loop.
+// The reason is forwarded to the remaining clients so the host can see why a player dropped.
+func UnregisterClient(room *core.Room, client *core.Client, reason core.DisconnectReason) {
+	room.Do(func() {
+		unregisterClient(room, client, reason)
+	})
+}
+
+// unregisterClient removes a client from a room with separated storage
+func unregisterClient(room *core.Room, client *core.Client, reason core.DisconnectReason) {
+	log.Printf("[ROOM %s] Unregistering client %s (IsHost: %t, reason: %s)", room.ID, client.Nickname, client.IsHost, reason)
+	if client.StopGrace != nil {
+		client.StopGrace()
+		client.StopGrace = nil
+	}
+	player := client.Player()
 
 	// Remove from appropriate storage
 	if client.IsHost && room.HostClient == client {
-		// Remove host
+		// Remove host; only a holder of the host secret may take over
 		room.HostClient = nil
 		log.Printf("[ROOM %s] Host %s removed", room.ID, client.Nickname)
-		
-		// Assign a new host from players if available
-		for c := range room.PlayerClients {
-			// Move player to host
-			delete(room.PlayerClients, c)
-			room.HostClient = c
-			c.IsHost = true
-			log.Printf("[ROOM %s] %s is now the new host", room.ID, c.Nickname)
-			break
-		}
+	} else if client.IsSpectator {
+		delete(room.SpectatorClients, client)
+		log.Printf("[ROOM %s] Spectator %s removed", room.ID, client.Nickname)
 	} else {
 		// Remove from player storage
 		delete(room.PlayerClients, client)
@@ -167,64 +281,166 @@ func UnregisterClient(room *core.Room, client *core.Client) {
 
 	// Remove from all clients
 	delete(room.AllClients, client)
-	room.TotalPlayers = len(room.AllClients)
+	room.TotalPlayers = countPlayers(room)
+
+	// Notify the active game
+	if room.Game != nil {
+		room.Game.OnPlayerLeave(client)
+	}
 
-	// Clean up empty rooms
-	if room.TotalPlayers == 0 {
+	// Tell the remaining clients who left and why
+	if len(room.AllClients) > 0 {
+		BroadcastToAllClients(room, map[string]interface{}{
+			"type": "playerLeft",
+			"data": map[string]interface{}{
+				"player":       player,
+				"reason":       reason,
+				"totalPlayers": len(room.PlayerClients),
+			},
+		})
+		broadcastPlayerListUpdate(room)
+	}
+
+	// Clean up empty rooms; spectators keep a room open
+	if len(room.AllClients) == 0 {
 		log.Printf("[ROOM %s] Room is empty, cleaning up", room.ID)
-		room.StopChan <- true
-		roomsMutex.Lock()
-		delete(rooms, room.ID)
-		roomsMutex.Unlock()
+		closeRoom(room)
 	}
 
 	log.Printf("[ROOM %s] Client %s unregistered. Total players: %d", room.ID, client.Nickname, room.TotalPlayers)
 }
 
-// handleReconnections handles reconnection requests for a room with separated storage
-func handleReconnections(room *core.Room) {
-	log.Printf("[ROOM %s] Starting reconnection handler", room.ID)
-	for {
-		select {
-		case req := <-room.ReconnectionChan:
-			log.Printf("[ROOM %s] Processing reconnection request for %s", room.ID, req.Client.Nickname)
-
-			// Check if a client with the same nickname exists in all clients
-			var existingClient *core.Client
-			for client := range room.AllClients {
-				if client.Nickname == req.Client.Nickname {
-					existingClient = client
-					break
-				}
-			}
+// closeRoom ends the room's game, removes the room and stops its event loop.
+// It must be called from the room's event loop.
+func closeRoom(room *core.Room) {
+	if room.Game != nil {
+		room.Game.End()
+	}
+	roomsMutex.Lock()
+	if rooms[room.ID] == room {
+		delete(rooms, room.ID)
+	}
+	roomsMutex.Unlock()
+	room.Stop()
+
+	if err := roomStore.CloseRoom(room.ID, time.Now()); err != nil {
+		log.Printf("[ROOM %s] Failed to record room close: %v", room.ID, err)
+	}
+}
+
+// CloseAll closes every live room, ending running games so their results are
+// recorded, and waits for each room's event loop to stop
+func CloseAll() {
+	roomsMutex.RLock()
+	live := make([]*core.Room, 0, len(rooms))
+	for _, room := range rooms {
+		live = append(live, room)
+	}
+	roomsMutex.RUnlock()
 
-			if existingClient != nil {
-				// Replace the old connection with the new one
-				log.Printf("[ROOM %s] Replacing connection for %s (IsHost: %t)", room.ID, req.Client.Nickname, existingClient.IsHost)
+	for _, room := range live {
+		room.Do(func() {
+			closeRoom(room)
+		})
+	}
+	log.Printf("[ROOM] Closed %d rooms", len(live))
+}
 
-				// Close old connection
-				existingClient.Conn.Close()
+// reconnectClient hands the seat whose player ID matches the client over to
+// the client's connection. A new host connection takes over the host seat.
+// It returns false if there is no such seat.
+func reconnectClient(room *core.Room, client *core.Client) bool {
+	existingClient := findClientByID(room, client.ID)
+	if existingClient == nil && client.IsHost {
+		existingClient = room.HostClient
+	}
+	if existingClient == nil {
+		return false
+	}
+	log.Printf("[ROOM %s] Resuming seat of %s (ID: %s, IsHost: %t, away: %t)",
+		room.ID, existingClient.Nickname, client.ID, existingClient.IsHost, existingClient.Away)
 
-				// Update connection and preserve game state
-				existingClient.Conn = req.Client.Conn
+	if existingClient.StopGrace != nil {
+		existingClient.StopGrace()
+		existingClient.StopGrace = nil
+	}
 
-				// Update host status if needed
-				if room.HostClient == existingClient {
-					existingClient.IsHost = true
-				}
+	// Keep score and host role, and swap the client in every storage
+	client.TakeSeat(existingClient)
+	if room.HostClient == existingClient {
+		room.HostClient = client
+	}
+	if room.PlayerClients[existingClient] {
+		delete(room.PlayerClients, existingClient)
+		room.PlayerClients[client] = true
+	}
+	if room.SpectatorClients[existingClient] {
+		delete(room.SpectatorClients, existingClient)
+		room.SpectatorClients[client] = true
+	}
+	delete(room.AllClients, existingClient)
+	room.AllClients[client] = true
 
-				req.Response <- true
-			} else {
-				// No existing client found, treat as new connection
-				log.Printf("[ROOM %s] No existing client found for %s, treating as new connection", room.ID, req.Client.Nickname)
-				req.Response <- false
-			}
+	// Drop the previous connection if it is still open
+	existingClient.Close(core.DisconnectReplaced)
 
-		case <-room.StopChan:
-			log.Printf("[ROOM %s] Stopping reconnection handler", room.ID)
-			return
+	BroadcastToAllClients(room, map[string]interface{}{
+		"type": "playerReturned",
+		"data": map[string]interface{}{
+			"player": client.Player(),
+		},
+	})
+	broadcastPlayerListUpdate(room)
+	sendResync(room, client)
+	return true
+}
+
+// sendResync sends a resumed client everything it needs to rejoin the running
+// round: the player's own seat, the room state and the active game's snapshot
+func sendResync(room *core.Room, client *core.Client) {
+	data := map[string]interface{}{
+		"roomId":      room.ID,
+		"player":      client.Player(),
+		"gameStarted": room.GameStarted,
+		"gameEnded":   room.GameEnded,
+		"paused":      room.Paused,
+		"timeLeft":    room.GameTime,
+	}
+	if room.Game != nil {
+		data["gameType"] = room.Game.Type()
+		if snapshotter, ok := room.Game.(core.PlayerSnapshotter); ok {
+			data["snapshot"] = snapshotter.PlayerSnapshot(client)
+		} else {
+			data["snapshot"] = room.Game.Snapshot()
+		}
+	}
+	if room.Tournament != nil {
+		data["tournament"] = map[string]interface{}{
+			"roundsPlayed": room.Tournament.Current,
+			"rounds":       room.Tournament.Rounds,
+			"standings":    room.Tournament.Table(),
+			"finished":     room.Tournament.Finished,
+		}
+	}
+
+	SendToClient(client, map[string]interface{}{
+		"type": "resync",
+		"data": data,
+	})
+	log.Printf("[ROOM %s] Sent resync to %s", room.ID, client.Nickname)
+}
+
+// findClientByID returns the client in the room with the given player ID
+func findClientByID(room *core.Room, playerID string) *core.Client {
+	if playerID == "" {
+		return nil
+	}
+	for c := range room.AllClients {
+		if c.ID == playerID {
+			return c
 		}
 	}
+	return nil
 }
 
 // BroadcastToRoom sends a message to all clients in a room (backward compatibility)
@@ -240,35 +456,42 @@ func BroadcastToAllClients(room *core.Room, message map[string]interface{}) {
 		return
 	}
 
+	logBroadcast(room, core.AudienceRoom, messageBytes)
+	droppable := core.IsDroppable(message)
 	for client := range room.AllClients {
-		client.Mutex.Lock()
-		err := client.Conn.WriteMessage(websocket.TextMessage, messageBytes)
-		client.Mutex.Unlock()
-		if err != nil {
-			log.Printf("Error broadcasting to %s: %v", client.Nickname, err)
-		}
+		client.SendRaw(messageBytes, droppable)
 	}
 }
 
 // BroadcastToHost sends a message only to the host
 func BroadcastToHost(room *core.Room, message map[string]interface{}) {
-	if room.HostClient == nil {
-		log.Printf("[ROOM %s] No host to broadcast to", room.ID)
-		return
-	}
-
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
 		log.Printf("Error marshaling host message: %v", err)
 		return
 	}
 
-	room.HostClient.Mutex.Lock()
-	err = room.HostClient.Conn.WriteMessage(websocket.TextMessage, messageBytes)
-	room.HostClient.Mutex.Unlock()
-	if err != nil {
-		log.Printf("Error broadcasting to host %s: %v", room.HostClient.Nickname, err)
+	logBroadcast(room, core.AudienceHost, messageBytes)
+	sendToHost(room, messageBytes, core.IsDroppable(message))
+}
+
+// sendToHost sends an encoded message to the host, if there is one
+func sendToHost(room *core.Room, messageBytes []byte, droppable bool) {
+	if room.HostClient == nil {
+		log.Printf("[ROOM %s] No host to broadcast to", room.ID)
+		return
 	}
+	room.HostClient.SendRaw(messageBytes, droppable)
+}
+
+// logBroadcast records a broadcast in the running game's event log
+func logBroadcast(room *core.Room, audience string, messageBytes []byte) {
+	room.LogEvent(core.Event{Kind: core.EventBroadcast, To: audience, Message: messageBytes})
+}
+
+// SendToClient sends a message to a single client
+func SendToClient(client *core.Client, message map[string]interface{}) {
+	client.Send(message)
 }
 
 // BroadcastToPlayers sends a message only to players (excluding host)
@@ -279,40 +502,64 @@ func BroadcastToPlayers(room *core.Room, message map[string]interface{}) {
 		return
 	}
 
+	logBroadcast(room, core.AudiencePlayers, messageBytes)
+	droppable := core.IsDroppable(message)
 	for client := range room.PlayerClients {
-		client.Mutex.Lock()
-		err := client.Conn.WriteMessage(websocket.TextMessage, messageBytes)
-		client.Mutex.Unlock()
-		if err != nil {
-			log.Printf("Error broadcasting to player %s: %v", client.Nickname, err)
-		}
+		client.SendRaw(messageBytes, droppable)
 	}
 }
 
+// BroadcastToSpectators sends a message only to spectators
+func BroadcastToSpectators(room *core.Room, message map[string]interface{}) {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling spectator message: %v", err)
+		return
+	}
+
+	logBroadcast(room, core.AudienceSpectators, messageBytes)
+	sendToSpectators(room, messageBytes, core.IsDroppable(message))
+}
+
+// sendToSpectators sends an encoded message to every spectator
+func sendToSpectators(room *core.Room, messageBytes []byte, droppable bool) {
+	for client := range room.SpectatorClients {
+		client.SendRaw(messageBytes, droppable)
+	}
+}
+
+// BroadcastToHostAndSpectators sends a message to the host and the spectators,
+// such as a live leaderboard the players do not see
+func BroadcastToHostAndSpectators(room *core.Room, message map[string]interface{}) {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling host message: %v", err)
+		return
+	}
+
+	logBroadcast(room, core.AudienceHostAndSpectators, messageBytes)
+	droppable := core.IsDroppable(message)
+	sendToHost(room, messageBytes, droppable)
+	sendToSpectators(room, messageBytes, droppable)
+}
+
+// countPlayers counts the host and players, leaving out spectators
+func countPlayers(room *core.Room) int {
+	return len(room.AllClients) - len(room.SpectatorClients)
+}
+
 // BroadcastPlayerListUpdate broadcasts player list update to all clients
 func BroadcastPlayerListUpdate(room *core.Room) {
 	players := []core.Player{}
 
 	// Add host if exists
 	if room.HostClient != nil {
-		players = append(players, core.Player{
-			Nickname: room.HostClient.Nickname,
-			ID:       room.HostClient.Nickname, // Use nickname as ID for simplicity
-			IsHost:   true,
-			Score:    room.HostClient.Score,
-			Avatar:   room.HostClient.Avatar,
-		})
+		players = append(players, room.HostClient.Player())
 	}
 
 	// Add player clients
 	for client := range room.PlayerClients {
-		players = append(players, core.Player{
-			Nickname: client.Nickname,
-			ID:       client.Nickname,
-			IsHost:   false,
-			Score:    client.Score,
-			Avatar:   client.Avatar,
-		})
+		players = append(players, client.Player())
 	}
 
 	playerListMsg := map[string]interface{}{
@@ -325,17 +572,12 @@ func BroadcastPlayerListUpdate(room *core.Room) {
 	BroadcastToAllClients(room, playerListMsg)
 }
 
-// GetRoomList returns a list of all active rooms
-func GetRoomList() []string {
+// GetRoomCount returns the number of active rooms
+func GetRoomCount() int {
 	roomsMutex.RLock()
 	defer roomsMutex.RUnlock()
 
-	roomList := make([]string, 0, len(rooms))
-	for roomID := range rooms {
-		roomList = append(roomList, roomID)
-	}
-
-	return roomList
+	return len(rooms)
 }
 
 // broadcastMessage sends a message to all clients in a room
@@ -346,13 +588,10 @@ func broadcastMessage(room *core.Room, message map[string]interface{}) {
 		return
 	}
 
+	logBroadcast(room, core.AudienceRoom, messageBytes)
+	droppable := core.IsDroppable(message)
 	for client := range room.AllClients {
-		client.Mutex.Lock()
-		err := client.Conn.WriteMessage(websocket.TextMessage, messageBytes)
-		client.Mutex.Unlock()
-		if err != nil {
-			log.Printf("Error broadcasting to %s: %v", client.Nickname, err)
-		}
+		client.SendRaw(messageBytes, droppable)
 	}
 }
 
@@ -363,13 +602,7 @@ func broadcastPlayerListUpdate(room *core.Room) {
 
 	// Only add player clients (主持人不顯示在玩家列表中)
 	for client := range room.PlayerClients {
-		players = append(players, core.Player{
-			Nickname: client.Nickname,
-			ID:       client.Nickname,
-			IsHost:   false,
-			Score:    client.Score,
-			Avatar:   client.Avatar,
-		})
+		players = append(players, client.Player())
 	}
 
 	playerListMsg := map[string]interface{}{
@@ -0,0 +1,152 @@
+package room
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gaming-platform/core"
+)
+
+// newTestClient creates a client without a connection; its messages stay queued
+func newTestClient(id string) *core.Client {
+	client := core.NewClient(nil, core.SendOptions{QueueSize: 1024})
+	client.ID = id
+	client.Nickname = id
+	return client
+}
+
+func TestRegisterClientsConcurrently(t *testing.T) {
+	room := CreateRoom(Options{GameType: "memory", Capacity: 10})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- RegisterClient(room, newTestClient(fmt.Sprintf("p%d", i)))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	joined, full := 0, 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			joined++
+		case errors.Is(err, ErrRoomFull):
+			full++
+		default:
+			t.Fatalf("RegisterClient: %v", err)
+		}
+	}
+	if joined != 10 || full != 10 {
+		t.Fatalf("joined %d and refused %d, want 10 and 10", joined, full)
+	}
+
+	var players, total int
+	room.Do(func() {
+		players = len(room.PlayerClients)
+		total = room.TotalPlayers
+	})
+	if players != 10 || total != 10 {
+		t.Fatalf("room has %d players (total %d), want 10", players, total)
+	}
+}
+
+func TestHostAndSpectatorsDoNotTakeSeats(t *testing.T) {
+	room := CreateRoom(Options{GameType: "memory", Capacity: 1})
+
+	host := newTestClient("host")
+	host.IsHost = true
+	spectator := newTestClient("spectator")
+	spectator.IsSpectator = true
+	for _, client := range []*core.Client{host, spectator, newTestClient("player")} {
+		if err := RegisterClient(room, client); err != nil {
+			t.Fatalf("RegisterClient(%s): %v", client.ID, err)
+		}
+	}
+
+	room.Do(func() {
+		if room.HostClient != host {
+			t.Error("host is not the room's host")
+		}
+		if len(room.PlayerClients) != 1 || len(room.SpectatorClients) != 1 {
+			t.Errorf("room has %d players and %d spectators, want 1 and 1", len(room.PlayerClients), len(room.SpectatorClients))
+		}
+		if room.TotalPlayers != 2 {
+			t.Errorf("TotalPlayers = %d, want 2", room.TotalPlayers)
+		}
+	})
+}
+
+func TestLastClientLeavingClosesRoom(t *testing.T) {
+	room := CreateRoom(Options{GameType: "memory"})
+	first, second := newTestClient("a"), newTestClient("b")
+	for _, client := range []*core.Client{first, second} {
+		if err := RegisterClient(room, client); err != nil {
+			t.Fatalf("RegisterClient(%s): %v", client.ID, err)
+		}
+	}
+
+	UnregisterClient(room, first, core.DisconnectClosed)
+	if room.Stopped() {
+		t.Fatal("room closed while a client is still in it")
+	}
+	UnregisterClient(room, second, core.DisconnectClosed)
+	if !room.Stopped() {
+		t.Fatal("room still running after its last client left")
+	}
+	if _, exists := GetRoom(room.ID); exists {
+		t.Fatal("closed room is still listed")
+	}
+	if err := RegisterClient(room, newTestClient("c")); !errors.Is(err, ErrRoomClosed) {
+		t.Fatalf("RegisterClient on a closed room = %v, want ErrRoomClosed", err)
+	}
+}
+
+func TestDisconnectedPlayerKeepsSeatUntilGraceExpires(t *testing.T) {
+	room := CreateRoom(Options{GameType: "memory"})
+	host := newTestClient("host")
+	host.IsHost = true
+	player := newTestClient("player")
+	for _, client := range []*core.Client{host, player} {
+		if err := RegisterClient(room, client); err != nil {
+			t.Fatalf("RegisterClient(%s): %v", client.ID, err)
+		}
+	}
+	room.Do(func() { player.Score = 40 })
+
+	DisconnectClient(room, player, core.DisconnectTimeout, time.Hour)
+	room.Do(func() {
+		if !room.PlayerClients[player] || !player.Away {
+			t.Error("disconnected player lost their seat during the grace period")
+		}
+	})
+
+	// A new connection with the same player ID takes the seat and score over
+	resumed := newTestClient("player")
+	if err := RegisterClient(room, resumed); err != nil {
+		t.Fatalf("RegisterClient(resumed): %v", err)
+	}
+	room.Do(func() {
+		if room.PlayerClients[player] || !room.PlayerClients[resumed] {
+			t.Error("resumed connection did not replace the old one")
+		}
+		if resumed.Score != 40 || resumed.Away {
+			t.Errorf("resumed seat has score %d, away %t; want 40, false", resumed.Score, resumed.Away)
+		}
+	})
+
+	DisconnectClient(room, resumed, core.DisconnectTimeout, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	room.Do(func() {
+		if room.PlayerClients[resumed] {
+			t.Error("player kept their seat after the grace period")
+		}
+	})
+}
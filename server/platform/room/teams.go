@@ -0,0 +1,193 @@
+package room
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"gaming-platform/core"
+)
+
+// NormalizeTeams trims and de-duplicates team names and fills in the default
+// scoring mode. It returns nil if fewer than two distinct teams remain.
+func NormalizeTeams(settings *core.TeamSettings) *core.TeamSettings {
+	if settings == nil {
+		return nil
+	}
+
+	normalized := &core.TeamSettings{Scoring: settings.Scoring}
+	seen := make(map[string]bool)
+	for _, name := range settings.Names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized.Names = append(normalized.Names, name)
+	}
+	if len(normalized.Names) < 2 {
+		return nil
+	}
+	if normalized.Scoring == "" {
+		normalized.Scoring = core.TeamScoringSum
+	}
+	return normalized
+}
+
+// SetTeams replaces the room's teams. Host assignments (player ID to team)
+// are applied first; with autoBalance every other player is dealt out again
+// so team sizes differ by at most one. Players left without a valid team join
+// the smallest one.
+func SetTeams(room *core.Room, settings *core.TeamSettings, autoBalance bool, assignments map[string]string) {
+	room.Teams = settings
+	if settings == nil {
+		for client := range room.PlayerClients {
+			client.Team = ""
+		}
+		broadcastTeamsUpdate(room)
+		return
+	}
+
+	valid := make(map[string]bool)
+	for _, name := range settings.Names {
+		valid[name] = true
+	}
+
+	// Deal players out in a stable order so balancing is predictable
+	players := make([]*core.Client, 0, len(room.PlayerClients))
+	for client := range room.PlayerClients {
+		players = append(players, client)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].ID < players[j].ID
+	})
+
+	for _, client := range players {
+		if team, assigned := assignments[client.ID]; assigned && valid[team] {
+			client.Team = team
+		} else if autoBalance || !valid[client.Team] {
+			client.Team = ""
+		}
+	}
+	for _, client := range players {
+		if client.Team == "" {
+			assignTeam(room, client)
+		}
+	}
+
+	log.Printf("[ROOM %s] Teams set to %v (scoring: %s, auto balance: %t)", room.ID, settings.Names, settings.Scoring, autoBalance)
+	broadcastTeamsUpdate(room)
+}
+
+// assignTeam puts a player in the team with the fewest players
+func assignTeam(room *core.Room, client *core.Client) {
+	if room.Teams == nil || client.IsHost {
+		return
+	}
+
+	sizes := teamSizes(room)
+	smallest := room.Teams.Names[0]
+	for _, name := range room.Teams.Names {
+		if sizes[name] < sizes[smallest] {
+			smallest = name
+		}
+	}
+	client.Team = smallest
+	log.Printf("[ROOM %s] %s assigned to team %s", room.ID, client.Nickname, smallest)
+}
+
+// teamSizes counts the players in each team
+func teamSizes(room *core.Room) map[string]int {
+	sizes := make(map[string]int)
+	for client := range room.PlayerClients {
+		if client.Team != "" {
+			sizes[client.Team]++
+		}
+	}
+	return sizes
+}
+
+// TeamStandings ranks the room's teams by the combined score of their
+// players. It returns nil when the room does not use teams.
+func TeamStandings(room *core.Room) []core.TeamStanding {
+	if room.Teams == nil {
+		return nil
+	}
+
+	totals := make(map[string]int)
+	sizes := make(map[string]int)
+	for client := range room.PlayerClients {
+		if client.Team != "" {
+			totals[client.Team] += client.Score
+			sizes[client.Team]++
+		}
+	}
+
+	standings := make([]core.TeamStanding, 0, len(room.Teams.Names))
+	for _, name := range room.Teams.Names {
+		score := float64(totals[name])
+		if room.Teams.Scoring == core.TeamScoringAverage && sizes[name] > 0 {
+			score /= float64(sizes[name])
+		}
+		standings = append(standings, core.TeamStanding{
+			Team:    name,
+			Score:   score,
+			Players: sizes[name],
+		})
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Score > standings[j].Score
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+	return standings
+}
+
+// WithTeamStandings adds the team standings to a leaderboard message when the room uses teams
+func WithTeamStandings(room *core.Room, message map[string]interface{}) map[string]interface{} {
+	if standings := TeamStandings(room); standings != nil {
+		message["teamStandings"] = standings
+	}
+	return message
+}
+
+// BroadcastTeamStandingsToPlayers sends the team standings to the players when
+// the room uses teams. Games whose live leaderboards go only to the host and
+// spectators call it so players can follow their team too.
+func BroadcastTeamStandingsToPlayers(room *core.Room, gameType string) {
+	standings := TeamStandings(room)
+	if standings == nil {
+		return
+	}
+	BroadcastToPlayers(room, map[string]interface{}{
+		"type":          "teamStandings",
+		"gameType":      gameType,
+		"teamStandings": standings,
+	})
+}
+
+// broadcastTeamsUpdate tells everyone in the room about the teams and who plays in them
+func broadcastTeamsUpdate(room *core.Room) {
+	members := make(map[string][]core.Player)
+	if room.Teams != nil {
+		for _, name := range room.Teams.Names {
+			members[name] = []core.Player{}
+		}
+		for client := range room.PlayerClients {
+			if client.Team != "" {
+				members[client.Team] = append(members[client.Team], client.Player())
+			}
+		}
+	}
+
+	BroadcastToAllClients(room, map[string]interface{}{
+		"type": "teamsUpdate",
+		"data": map[string]interface{}{
+			"teams":   room.Teams,
+			"members": members,
+		},
+	})
+	broadcastPlayerListUpdate(room)
+}
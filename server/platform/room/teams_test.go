@@ -0,0 +1,94 @@
+package room
+
+import (
+	"fmt"
+	"testing"
+
+	"gaming-platform/core"
+)
+
+// teamRoom creates a room with the given number of players and no teams
+func teamRoom(t *testing.T, players int) (*core.Room, []*core.Client) {
+	t.Helper()
+	room := CreateRoom(Options{GameType: "memory", Capacity: players})
+	clients := make([]*core.Client, players)
+	for i := range clients {
+		clients[i] = newTestClient(fmt.Sprintf("p%d", i))
+		if err := RegisterClient(room, clients[i]); err != nil {
+			t.Fatalf("RegisterClient(%s): %v", clients[i].ID, err)
+		}
+	}
+	return room, clients
+}
+
+func TestSetTeamsAutoBalance(t *testing.T) {
+	room, clients := teamRoom(t, 7)
+	settings := NormalizeTeams(&core.TeamSettings{Names: []string{"Red", "Blue", "Green"}})
+
+	room.Do(func() {
+		// Stale teams from an earlier setup are ignored when balancing
+		for _, client := range clients {
+			client.Team = "Red"
+		}
+		SetTeams(room, settings, true, nil)
+
+		sizes := teamSizes(room)
+		if sizes["Red"] != 3 || sizes["Blue"] != 2 || sizes["Green"] != 2 {
+			t.Errorf("team sizes = %v, want Red 3, Blue 2, Green 2", sizes)
+		}
+	})
+}
+
+func TestSetTeamsKeepsHostAssignments(t *testing.T) {
+	room, clients := teamRoom(t, 4)
+	settings := NormalizeTeams(&core.TeamSettings{Names: []string{"Red", "Blue"}})
+	assignments := map[string]string{"p0": "Red", "p1": "Red", "p2": "Purple"}
+
+	room.Do(func() {
+		SetTeams(room, settings, true, assignments)
+
+		if clients[0].Team != "Red" || clients[1].Team != "Red" {
+			t.Errorf("assigned players are in %q and %q, want Red", clients[0].Team, clients[1].Team)
+		}
+		// An unknown team is ignored, so the rest fill up Blue
+		if clients[2].Team != "Blue" || clients[3].Team != "Blue" {
+			t.Errorf("other players are in %q and %q, want Blue", clients[2].Team, clients[3].Team)
+		}
+	})
+}
+
+func TestSetTeamsWithoutBalanceKeepsValidTeams(t *testing.T) {
+	room, clients := teamRoom(t, 3)
+	settings := NormalizeTeams(&core.TeamSettings{Names: []string{"Red", "Blue"}})
+
+	room.Do(func() {
+		clients[0].Team = "Red"
+		clients[1].Team = "Red"
+		clients[2].Team = "Gone"
+		SetTeams(room, settings, false, nil)
+
+		if clients[0].Team != "Red" || clients[1].Team != "Red" || clients[2].Team != "Blue" {
+			t.Errorf("teams = %q, %q, %q; want Red, Red, Blue", clients[0].Team, clients[1].Team, clients[2].Team)
+		}
+
+		SetTeams(room, nil, false, nil)
+		for _, client := range clients {
+			if client.Team != "" {
+				t.Errorf("%s still in team %q after teams were cleared", client.ID, client.Team)
+			}
+		}
+	})
+}
+
+func TestNormalizeTeams(t *testing.T) {
+	settings := NormalizeTeams(&core.TeamSettings{Names: []string{" Red ", "Red", "", "Blue"}})
+	if settings == nil || len(settings.Names) != 2 || settings.Names[0] != "Red" || settings.Names[1] != "Blue" {
+		t.Fatalf("NormalizeTeams = %+v, want [Red Blue]", settings)
+	}
+	if settings.Scoring != core.TeamScoringSum {
+		t.Errorf("Scoring = %q, want %q", settings.Scoring, core.TeamScoringSum)
+	}
+	if NormalizeTeams(&core.TeamSettings{Names: []string{"Red", " Red"}}) != nil {
+		t.Error("a single distinct team was accepted")
+	}
+}
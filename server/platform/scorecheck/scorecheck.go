@@ -0,0 +1,169 @@
+// Package scorecheck checks client-reported scores against what a game allows
+package scorecheck
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gaming-platform/core"
+	"gaming-platform/core/message"
+	"gaming-platform/platform/room"
+)
+
+// maxAuditEntries caps the audit trail kept per room; the oldest entries go first
+const maxAuditEntries = 500
+
+// Submission is a score reported by a player
+type Submission struct {
+	Previous int           // The player's score before the report
+	Reported int           // The score the client sent
+	Elapsed  time.Duration // Time since the game started
+	Ended    bool          // Whether the game has already ended
+}
+
+// Validator decides which score to accept for a submission. It returns the
+// accepted score with the reason when it is lower than reported, or an error
+// when the submission is refused outright.
+type Validator interface {
+	Validate(submission Submission) (accepted int, reason string, err error)
+}
+
+// ValidatorFunc adapts a function to the Validator interface
+type ValidatorFunc func(submission Submission) (int, string, error)
+
+// Validate calls the function
+func (f ValidatorFunc) Validate(submission Submission) (int, string, error) {
+	return f(submission)
+}
+
+// Limits is a validator bounding scores by game settings and elapsed time.
+// Zero values switch the matching check off.
+type Limits struct {
+	MaxPointsPerSecond float64 // Sustained scoring rate a player can reach
+	Burst              int     // Points allowed on top of the rate, e.g. for a quick start
+	MaxScore           int     // Highest score the game can give
+	AllowDecrease      bool    // Scores normally only go up
+}
+
+// Validate refuses reports after the game has ended and scores that drop,
+// and clamps scores above the maximum or the rate limit
+func (l Limits) Validate(submission Submission) (int, string, error) {
+	if submission.Ended {
+		return 0, "", core.NewError(core.ErrCodeGameEnded, "the game has already ended")
+	}
+	if submission.Reported < 0 {
+		return 0, "", core.NewError(core.ErrCodeScoreRejected, "score %d is negative", submission.Reported)
+	}
+	if !l.AllowDecrease && submission.Reported < submission.Previous {
+		return 0, "", core.NewError(core.ErrCodeScoreRejected, "score cannot drop from %d to %d", submission.Previous, submission.Reported)
+	}
+
+	accepted, reason := submission.Reported, ""
+	if l.MaxScore > 0 && accepted > l.MaxScore {
+		accepted = l.MaxScore
+		reason = fmt.Sprintf("score %d is above the game's maximum of %d", submission.Reported, l.MaxScore)
+	}
+	if l.MaxPointsPerSecond > 0 {
+		allowed := l.Burst + int(l.MaxPointsPerSecond*submission.Elapsed.Seconds())
+		if accepted > allowed {
+			accepted = allowed
+			reason = fmt.Sprintf("score %d after %.0fs is faster than %g points per second", submission.Reported, submission.Elapsed.Seconds(), l.MaxPointsPerSecond)
+		}
+	}
+
+	// Clamping never takes away points that were already accepted, though a
+	// game allowing decreases may still lower the score itself
+	floor := submission.Previous
+	if submission.Reported < floor {
+		floor = submission.Reported
+	}
+	if accepted < floor {
+		accepted = floor
+	}
+	return accepted, reason, nil
+}
+
+// init registers the host's audit request
+func init() {
+	message.RegisterHostHandler("hostGetScoreAudit", handleGetScoreAudit, nil)
+}
+
+// Check runs a player's reported score through a game's validator and returns
+// the score to keep. Refused and clamped reports go into the room's audit
+// trail and the host is told about the player. It must be called on the
+// room's event loop.
+func Check(gameRoom *core.Room, gameType string, validator Validator, client *core.Client, submission Submission) (int, error) {
+	accepted, reason, err := validator.Validate(submission)
+
+	entry := core.ScoreAuditEntry{
+		Time:     time.Now(),
+		GameType: gameType,
+		PlayerID: client.ID,
+		Nickname: client.Nickname,
+		Previous: submission.Previous,
+		Reported: submission.Reported,
+		Accepted: accepted,
+		Action:   core.ScoreClamped,
+		Reason:   reason,
+	}
+	switch {
+	case err != nil:
+		entry.Accepted = submission.Previous
+		entry.Action = core.ScoreRejected
+		entry.Reason = err.Error()
+		if coreErr, ok := err.(*core.Error); ok {
+			entry.Reason = coreErr.Message
+		}
+	case accepted == submission.Reported:
+		return accepted, nil
+	default:
+		// Let the client correct the score it shows
+		room.SendToClient(client, map[string]interface{}{
+			"type":   "scoreCorrected",
+			"score":  accepted,
+			"reason": reason,
+		})
+	}
+
+	audit(gameRoom, entry)
+	return accepted, err
+}
+
+// audit appends an entry to the room's audit trail and flags the player to the host
+func audit(gameRoom *core.Room, entry core.ScoreAuditEntry) {
+	log.Printf("[SCORECHECK] %s score from %s in room %s: reported %d, kept %d (%s)",
+		entry.Action, entry.Nickname, gameRoom.ID, entry.Reported, entry.Accepted, entry.Reason)
+
+	gameRoom.ScoreAudit = append(gameRoom.ScoreAudit, entry)
+	if len(gameRoom.ScoreAudit) > maxAuditEntries {
+		gameRoom.ScoreAudit = gameRoom.ScoreAudit[len(gameRoom.ScoreAudit)-maxAuditEntries:]
+	}
+
+	flags := 0
+	for _, recorded := range gameRoom.ScoreAudit {
+		if recorded.PlayerID == entry.PlayerID {
+			flags++
+		}
+	}
+
+	room.BroadcastToHost(gameRoom, map[string]interface{}{
+		"type":  "scoreFlagged",
+		"entry": entry,
+		"flags": flags,
+	})
+}
+
+// handleGetScoreAudit sends the room's audit trail to the host
+func handleGetScoreAudit(gameRoom *core.Room, client *core.Client, msg core.Message) error {
+	entries := gameRoom.ScoreAudit
+	if entries == nil {
+		entries = []core.ScoreAuditEntry{}
+	}
+
+	room.SendToClient(client, map[string]interface{}{
+		"type":    "scoreAudit",
+		"entries": entries,
+	})
+	return nil
+}
@@ -0,0 +1,61 @@
+package scorecheck
+
+import (
+	"testing"
+	"time"
+
+	"gaming-platform/core"
+)
+
+func TestLimitsValidate(t *testing.T) {
+	limits := Limits{MaxPointsPerSecond: 10, Burst: 20, MaxScore: 500}
+
+	tests := []struct {
+		name       string
+		submission Submission
+		accepted   int
+		clamped    bool
+		errCode    string
+	}{
+		{"within limits", Submission{Previous: 10, Reported: 60, Elapsed: 5 * time.Second}, 60, false, ""},
+		{"burst at start", Submission{Reported: 20}, 20, false, ""},
+		{"too fast", Submission{Previous: 10, Reported: 200, Elapsed: 5 * time.Second}, 70, true, ""},
+		{"above maximum", Submission{Previous: 400, Reported: 900, Elapsed: time.Hour}, 500, true, ""},
+		{"clamp keeps earlier points", Submission{Previous: 100, Reported: 150, Elapsed: time.Second}, 100, true, ""},
+		{"dropped score", Submission{Previous: 50, Reported: 40, Elapsed: time.Minute}, 0, false, core.ErrCodeScoreRejected},
+		{"negative score", Submission{Reported: -1, Elapsed: time.Minute}, 0, false, core.ErrCodeScoreRejected},
+		{"after the end", Submission{Previous: 10, Reported: 20, Elapsed: time.Minute, Ended: true}, 0, false, core.ErrCodeGameEnded},
+	}
+	for _, test := range tests {
+		accepted, reason, err := limits.Validate(test.submission)
+		if test.errCode != "" {
+			coreErr, ok := err.(*core.Error)
+			if !ok || coreErr.Code != test.errCode {
+				t.Errorf("%s: err = %v, want code %s", test.name, err, test.errCode)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if accepted != test.accepted {
+			t.Errorf("%s: accepted %d, want %d", test.name, accepted, test.accepted)
+		}
+		if clamped := reason != ""; clamped != test.clamped {
+			t.Errorf("%s: reason = %q, want clamped %t", test.name, reason, test.clamped)
+		}
+	}
+}
+
+func TestZeroLimitsOnlyGuardDirection(t *testing.T) {
+	accepted, reason, err := Limits{}.Validate(Submission{Reported: 1 << 20})
+	if err != nil || accepted != 1<<20 || reason != "" {
+		t.Fatalf("Validate = %d, %q, %v; want the report unchanged", accepted, reason, err)
+	}
+
+	accepted, _, err = Limits{AllowDecrease: true}.Validate(Submission{Previous: 50, Reported: 40})
+	if err != nil || accepted != 40 {
+		t.Fatalf("Validate with AllowDecrease = %d, %v; want 40", accepted, err)
+	}
+}
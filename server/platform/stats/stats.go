@@ -0,0 +1,68 @@
+// Package stats keeps lifetime statistics for registered players
+package stats
+
+import (
+	"errors"
+	"log"
+	"sync"
+
+	"gaming-platform/platform/store"
+)
+
+// Global stats state
+var (
+	playerStats store.StatsStore = store.NewMemoryStore()
+	statsMutex                   = sync.Mutex{}
+)
+
+// SetStore replaces the store player statistics are kept in
+func SetStore(ss store.StatsStore) {
+	playerStats = ss
+}
+
+// Record adds a finished game to the statistics of every registered player in it.
+// Guests without a user ID are skipped.
+func Record(result *store.GameResult) {
+	// Updates are read-modify-write, so games ending in different rooms take turns
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+
+	for _, player := range result.Players {
+		if player.UserID == "" {
+			continue
+		}
+
+		stats, err := playerStats.GetStats(player.UserID, result.GameType)
+		if errors.Is(err, store.ErrStatsNotFound) {
+			stats = &store.PlayerStats{
+				UserID:   player.UserID,
+				GameType: result.GameType,
+			}
+		} else if err != nil {
+			log.Printf("[STATS] Failed to load %s stats for user %s: %v", result.GameType, player.UserID, err)
+			continue
+		}
+
+		stats.GamesPlayed++
+		if player.Rank == 1 {
+			stats.Wins++
+		}
+		if stats.GamesPlayed == 1 || player.Score > stats.BestScore {
+			stats.BestScore = player.Score
+		}
+		stats.TotalScore += player.Score
+		stats.RankSum += player.Rank
+		stats.AverageRank = float64(stats.RankSum) / float64(stats.GamesPlayed)
+		stats.TotalCollected += player.Collected
+		stats.UpdatedAt = result.EndedAt
+
+		if err := playerStats.SaveStats(stats); err != nil {
+			log.Printf("[STATS] Failed to save %s stats for user %s: %v", result.GameType, player.UserID, err)
+		}
+	}
+}
+
+// ForUser returns a user's statistics for every game type they played
+func ForUser(userID string) ([]*store.PlayerStats, error) {
+	return playerStats.ListStats(userID)
+}
@@ -0,0 +1,385 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// maxBatchedWrites caps how many queued writes share one transaction
+const maxBatchedWrites = 256
+
+// Delays between attempts to commit a batch that failed, doubling up to the maximum
+const (
+	minRetryDelay = 100 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+// Buckets of the bolt database
+var (
+	usersBucket   = []byte("users")
+	roomsBucket   = []byte("rooms")
+	resultsBucket = []byte("results")
+	statsBucket   = []byte("stats")
+	eventsBucket  = []byte("events")
+)
+
+// BoltStore keeps users, rooms, results and statistics in memory and writes
+// every change to a bbolt database. Writes are queued and committed by a
+// background writer, batching whatever is queued into one synced
+// transaction. The queue grows rather than blocks, so callers on a room's
+// event loop never wait for the disk. Event logs are only held in memory
+// until they are written.
+type BoltStore struct {
+	*MemoryStore
+	db      *bolt.DB
+	wake    chan struct{} // Signals the writer that writes were queued or the store closed
+	closing chan struct{} // Closed by Close to cut a retry delay short
+	stopped chan struct{}
+
+	queueMutex sync.Mutex
+	queued     []boltWrite // Writes waiting for the writer, oldest first
+	closed     bool
+	writeErr   error // Why the last commit failed, nil once one succeeds
+
+	pendingMutex  sync.Mutex
+	pendingEvents map[string][]byte // Event logs queued but not yet committed
+}
+
+// boltWrite is one queued key update
+type boltWrite struct {
+	bucket []byte
+	key    string
+	value  []byte
+}
+
+// userRecord saves a user together with the password hash that User hides
+type userRecord struct {
+	*User
+	PasswordHash string `json:"passwordHash"`
+}
+
+// OpenBoltStore opens the bolt database at path, creating it on first use,
+// and loads it into memory
+func OpenBoltStore(path string) (*BoltStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create store directory: %w", err)
+		}
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open store database %s: %w", path, err)
+	}
+
+	s := &BoltStore{
+		MemoryStore:   NewMemoryStore(),
+		db:            db,
+		wake:          make(chan struct{}, 1),
+		closing:       make(chan struct{}),
+		stopped:       make(chan struct{}),
+		pendingEvents: make(map[string][]byte),
+	}
+	if err := s.load(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	go s.writeLoop()
+
+	log.Printf("[STORE] Opened %s: %d users, %d rooms, %d results, %d player stats",
+		path, len(s.users), len(s.rooms), len(s.results), len(s.stats))
+	return s, nil
+}
+
+// CreateUser stores a new user
+func (s *BoltStore) CreateUser(user *User) error {
+	if err := s.MemoryStore.CreateUser(user); err != nil {
+		return err
+	}
+	return s.queue(usersBucket, user.ID, userRecord{User: user, PasswordHash: user.PasswordHash})
+}
+
+// SaveRoom creates or replaces a room record
+func (s *BoltStore) SaveRoom(room *Room) error {
+	if err := s.MemoryStore.SaveRoom(room); err != nil {
+		return err
+	}
+	return s.queue(roomsBucket, room.ID, room)
+}
+
+// CloseRoom records when a room was closed
+func (s *BoltStore) CloseRoom(id string, closedAt time.Time) error {
+	if err := s.MemoryStore.CloseRoom(id, closedAt); err != nil {
+		return err
+	}
+	room, err := s.MemoryStore.GetRoom(id)
+	if err != nil {
+		return err
+	}
+	return s.queue(roomsBucket, id, room)
+}
+
+// SaveResult stores a finished game
+func (s *BoltStore) SaveResult(result *GameResult) error {
+	if err := s.MemoryStore.SaveResult(result); err != nil {
+		return err
+	}
+	return s.queue(resultsBucket, result.ID, result)
+}
+
+// SaveStats creates or replaces a user's statistics for a game type
+func (s *BoltStore) SaveStats(stats *PlayerStats) error {
+	if err := s.MemoryStore.SaveStats(stats); err != nil {
+		return err
+	}
+	return s.queue(statsBucket, statsKey(stats.UserID, stats.GameType), stats)
+}
+
+// SaveEvents queues a game's event log; it is served from memory until written
+func (s *BoltStore) SaveEvents(resultID string, events []byte) error {
+	events = append([]byte(nil), events...)
+	s.pendingMutex.Lock()
+	s.pendingEvents[resultID] = events
+	s.pendingMutex.Unlock()
+
+	return s.send(boltWrite{bucket: eventsBucket, key: resultID, value: events})
+}
+
+// GetEvents returns the event log of a recorded game
+func (s *BoltStore) GetEvents(resultID string) ([]byte, error) {
+	s.pendingMutex.Lock()
+	events, pending := s.pendingEvents[resultID]
+	s.pendingMutex.Unlock()
+	if pending {
+		return events, nil
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		stored := tx.Bucket(eventsBucket).Get([]byte(resultID))
+		if stored == nil {
+			return ErrEventsNotFound
+		}
+		events = append([]byte(nil), stored...)
+		return nil
+	})
+	return events, err
+}
+
+// Close writes everything still queued and closes the database. Writes
+// arriving afterwards fail with ErrStoreClosed.
+func (s *BoltStore) Close() error {
+	s.queueMutex.Lock()
+	if s.closed {
+		s.queueMutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.queueMutex.Unlock()
+	close(s.closing)
+	s.signal()
+
+	<-s.stopped
+	return s.db.Close()
+}
+
+// queue encodes a record and hands it to the background writer
+func (s *BoltStore) queue(bucket []byte, key string, record interface{}) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode %s record %s: %w", bucket, key, err)
+	}
+	return s.send(boltWrite{bucket: bucket, key: key, value: value})
+}
+
+// send appends a write to the queue unless the store is closed
+func (s *BoltStore) send(write boltWrite) error {
+	s.queueMutex.Lock()
+	if s.closed {
+		s.queueMutex.Unlock()
+		return ErrStoreClosed
+	}
+	s.queued = append(s.queued, write)
+	s.queueMutex.Unlock()
+	s.signal()
+	return nil
+}
+
+// signal wakes the writer without waiting for it
+func (s *BoltStore) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Err returns the error of the last failed commit while its writes are still
+// queued for a retry, or nil
+func (s *BoltStore) Err() error {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+	return s.writeErr
+}
+
+// requeue puts a batch that failed back at the front of the queue, so it is
+// retried before, and stays overwritten by, any later writes, and records the
+// failure
+func (s *BoltStore) requeue(batch []boltWrite, err error) {
+	s.queueMutex.Lock()
+	s.queued = append(batch, s.queued...)
+	s.writeErr = err
+	s.queueMutex.Unlock()
+}
+
+// nextBatch takes up to maxBatchedWrites writes off the queue and reports
+// whether the store has been closed
+func (s *BoltStore) nextBatch() ([]boltWrite, bool) {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+
+	n := len(s.queued)
+	if n > maxBatchedWrites {
+		n = maxBatchedWrites
+	}
+	batch := append([]boltWrite(nil), s.queued[:n]...)
+	s.queued = s.queued[n:]
+	if len(s.queued) == 0 {
+		s.queued = nil
+	}
+	return batch, s.closed
+}
+
+// writeLoop commits queued writes until the store is closed and the queue is
+// empty. Each transaction takes the writes waiting at the time, and bolt
+// syncs it to disk before it returns. A batch that fails stays queued and is
+// retried with a growing delay; once the store is closed it is given up on.
+func (s *BoltStore) writeLoop() {
+	defer close(s.stopped)
+
+	delay := minRetryDelay
+	for {
+		batch, closed := s.nextBatch()
+		if len(batch) == 0 {
+			if closed {
+				return
+			}
+			<-s.wake
+			continue
+		}
+
+		if err := s.commit(batch); err != nil {
+			if closed {
+				s.queueMutex.Lock()
+				lost := len(batch) + len(s.queued)
+				s.queueMutex.Unlock()
+				log.Printf("[STORE] Failed to write %d records while closing, %d records are lost: %v", len(batch), lost, err)
+				return
+			}
+			log.Printf("[STORE] Failed to write %d records, retrying in %s: %v", len(batch), delay, err)
+			s.requeue(batch, err)
+
+			select {
+			case <-time.After(delay):
+			case <-s.closing:
+			}
+			delay *= 2
+			if delay > maxRetryDelay {
+				delay = maxRetryDelay
+			}
+			continue
+		}
+		delay = minRetryDelay
+		s.queueMutex.Lock()
+		s.writeErr = nil
+		s.queueMutex.Unlock()
+
+		// Committed event logs are read back from the database from now on
+		s.pendingMutex.Lock()
+		for _, write := range batch {
+			if string(write.bucket) == string(eventsBucket) {
+				delete(s.pendingEvents, write.key)
+			}
+		}
+		s.pendingMutex.Unlock()
+	}
+}
+
+// commit writes a batch in one transaction
+func (s *BoltStore) commit(batch []boltWrite) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, write := range batch {
+			bucket := tx.Bucket(write.bucket)
+			if bucket == nil {
+				return fmt.Errorf("%s bucket is missing", write.bucket)
+			}
+			if err := bucket.Put([]byte(write.key), write.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// load creates the buckets and reads every record except event logs into memory
+func (s *BoltStore) load() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{usersBucket, roomsBucket, resultsBucket, statsBucket, eventsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("create %s bucket: %w", name, err)
+			}
+		}
+
+		err := tx.Bucket(usersBucket).ForEach(func(key, value []byte) error {
+			record := userRecord{User: &User{}}
+			if err := json.Unmarshal(value, &record); err != nil {
+				return fmt.Errorf("parse user %s: %w", key, err)
+			}
+			user := record.User
+			user.PasswordHash = record.PasswordHash
+			s.users[user.ID] = user
+			s.byEmail[normalizeEmail(user.Email)] = user
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = tx.Bucket(roomsBucket).ForEach(func(key, value []byte) error {
+			room := &Room{}
+			if err := json.Unmarshal(value, room); err != nil {
+				return fmt.Errorf("parse room %s: %w", key, err)
+			}
+			s.rooms[room.ID] = room
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = tx.Bucket(resultsBucket).ForEach(func(key, value []byte) error {
+			result := &GameResult{}
+			if err := json.Unmarshal(value, result); err != nil {
+				return fmt.Errorf("parse result %s: %w", key, err)
+			}
+			s.results[result.ID] = result
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(statsBucket).ForEach(func(key, value []byte) error {
+			stats := &PlayerStats{}
+			if err := json.Unmarshal(value, stats); err != nil {
+				return fmt.Errorf("parse stats %s: %w", key, err)
+			}
+			s.stats[statsKey(stats.UserID, stats.GameType)] = stats
+			return nil
+		})
+	})
+}
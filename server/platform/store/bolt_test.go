@@ -0,0 +1,144 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTestStore opens a bolt store in a temporary directory
+func openTestStore(t *testing.T, path string) *BoltStore {
+	t.Helper()
+	s, err := OpenBoltStore(path)
+	if err != nil {
+		t.Fatalf("OpenBoltStore: %v", err)
+	}
+	return s
+}
+
+func TestBoltStoreRefusesWritesAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	s := openTestStore(t, path)
+	if err := s.SaveResult(&GameResult{ID: "r1", RoomID: "ABC234"}); err != nil {
+		t.Fatalf("SaveResult: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if err := s.SaveResult(&GameResult{ID: "r2", RoomID: "ABC234"}); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("SaveResult after Close = %v, want ErrStoreClosed", err)
+	}
+	if err := s.SaveEvents("r2", []byte("[]")); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("SaveEvents after Close = %v, want ErrStoreClosed", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// Writes queued before Close were committed
+	reopened := openTestStore(t, path)
+	defer reopened.Close()
+	if _, err := reopened.GetResult("r1"); err != nil {
+		t.Errorf("GetResult(r1) after reopening: %v", err)
+	}
+}
+
+func TestBoltStoreCommitsEveryQueuedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	s := openTestStore(t, path)
+	// More writes than fit in one batch, queued faster than they are committed
+	for i := 0; i < 3*maxBatchedWrites; i++ {
+		if err := s.SaveResult(&GameResult{ID: fmt.Sprintf("r%d", i), RoomID: "ABC234"}); err != nil {
+			t.Fatalf("SaveResult: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened := openTestStore(t, path)
+	defer reopened.Close()
+	if len(reopened.results) != 3*maxBatchedWrites {
+		t.Fatalf("%d results after reopening, want %d", len(reopened.results), 3*maxBatchedWrites)
+	}
+}
+
+func TestBoltStoreRetriesFailedWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	s := openTestStore(t, path)
+	setBucket := func(create bool) {
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			if create {
+				_, err := tx.CreateBucket(resultsBucket)
+				return err
+			}
+			return tx.DeleteBucket(resultsBucket)
+		})
+		if err != nil {
+			t.Fatalf("update results bucket: %v", err)
+		}
+	}
+
+	// Without its bucket every commit of a result fails
+	setBucket(false)
+	if err := s.SaveResult(&GameResult{ID: "r1", RoomID: "ABC234"}); err != nil {
+		t.Fatalf("SaveResult: %v", err)
+	}
+	waitFor(t, "the write to fail", func() bool { return s.Err() != nil })
+
+	setBucket(true)
+	waitFor(t, "the retry to succeed", func() bool { return s.Err() == nil })
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened := openTestStore(t, path)
+	defer reopened.Close()
+	if _, err := reopened.GetResult("r1"); err != nil {
+		t.Errorf("GetResult(r1) after the retry: %v", err)
+	}
+}
+
+func TestBoltStoreCloseGivesUpOnFailingWrites(t *testing.T) {
+	s := openTestStore(t, filepath.Join(t.TempDir(), "store.db"))
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(resultsBucket)
+	})
+	if err != nil {
+		t.Fatalf("delete results bucket: %v", err)
+	}
+	if err := s.SaveResult(&GameResult{ID: "r1", RoomID: "ABC234"}); err != nil {
+		t.Fatalf("SaveResult: %v", err)
+	}
+	waitFor(t, "the write to fail", func() bool { return s.Err() != nil })
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- s.Close()
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close kept retrying a failing write")
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,231 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps all state in memory; it is lost on restart
+type MemoryStore struct {
+	users   map[string]*User
+	byEmail map[string]*User
+	rooms   map[string]*Room
+	results map[string]*GameResult
+	stats   map[string]*PlayerStats
+	events  map[string][]byte // JSON Lines event logs keyed by result ID
+	mutex   sync.RWMutex
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:   make(map[string]*User),
+		byEmail: make(map[string]*User),
+		rooms:   make(map[string]*Room),
+		results: make(map[string]*GameResult),
+		stats:   make(map[string]*PlayerStats),
+		events:  make(map[string][]byte),
+	}
+}
+
+// CreateUser stores a new user
+func (s *MemoryStore) CreateUser(user *User) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	email := normalizeEmail(user.Email)
+	if _, exists := s.byEmail[email]; exists {
+		return ErrEmailTaken
+	}
+	stored := *user
+	s.users[user.ID] = &stored
+	s.byEmail[email] = &stored
+	return nil
+}
+
+// GetUser returns the user with the given ID
+func (s *MemoryStore) GetUser(id string) (*User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, exists := s.users[id]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+// GetUserByEmail returns the user registered with the given email
+func (s *MemoryStore) GetUserByEmail(email string) (*User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, exists := s.byEmail[normalizeEmail(email)]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+// SaveRoom creates or replaces a room record
+func (s *MemoryStore) SaveRoom(room *Room) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := *room
+	s.rooms[room.ID] = &stored
+	return nil
+}
+
+// GetRoom returns the room with the given ID
+func (s *MemoryStore) GetRoom(id string) (*Room, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	room, exists := s.rooms[id]
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+	found := *room
+	return &found, nil
+}
+
+// CloseRoom records when a room was closed
+func (s *MemoryStore) CloseRoom(id string, closedAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	room, exists := s.rooms[id]
+	if !exists {
+		return ErrRoomNotFound
+	}
+	room.ClosedAt = &closedAt
+	return nil
+}
+
+// SaveResult stores a finished game
+func (s *MemoryStore) SaveResult(result *GameResult) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := *result
+	s.results[result.ID] = &stored
+	return nil
+}
+
+// GetResult returns the result with the given ID
+func (s *MemoryStore) GetResult(id string) (*GameResult, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result, exists := s.results[id]
+	if !exists {
+		return nil, ErrResultNotFound
+	}
+	found := *result
+	return &found, nil
+}
+
+// ListResults returns a room's results, oldest first
+func (s *MemoryStore) ListResults(roomID string) ([]*GameResult, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	results := []*GameResult{}
+	for _, result := range s.results {
+		if result.RoomID == roomID {
+			found := *result
+			results = append(results, &found)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].EndedAt.Before(results[j].EndedAt)
+	})
+	return results, nil
+}
+
+// GetStats returns a user's statistics for a game type
+func (s *MemoryStore) GetStats(userID, gameType string) (*PlayerStats, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats, exists := s.stats[statsKey(userID, gameType)]
+	if !exists {
+		return nil, ErrStatsNotFound
+	}
+	found := *stats
+	return &found, nil
+}
+
+// ListStats returns a user's statistics for every game type they played
+func (s *MemoryStore) ListStats(userID string) ([]*PlayerStats, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := []*PlayerStats{}
+	for _, stats := range s.stats {
+		if stats.UserID == userID {
+			found := *stats
+			list = append(list, &found)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].GameType < list[j].GameType
+	})
+	return list, nil
+}
+
+// SaveStats creates or replaces a user's statistics for a game type
+func (s *MemoryStore) SaveStats(stats *PlayerStats) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := *stats
+	s.stats[statsKey(stats.UserID, stats.GameType)] = &stored
+	return nil
+}
+
+// Err is always nil for the in-memory store
+func (s *MemoryStore) Err() error {
+	return nil
+}
+
+// Close does nothing for the in-memory store
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// normalizeEmail makes email lookups case-insensitive
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// statsKey identifies a user's statistics for a game type
+func statsKey(userID, gameType string) string {
+	return userID + "/" + gameType
+}
+
+// SaveEvents stores a game's event log under its result ID
+func (s *MemoryStore) SaveEvents(resultID string, events []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.events[resultID] = append([]byte(nil), events...)
+	return nil
+}
+
+// GetEvents returns the event log of a recorded game
+func (s *MemoryStore) GetEvents(resultID string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	events, exists := s.events[resultID]
+	if !exists {
+		return nil, ErrEventsNotFound
+	}
+	return events, nil
+}
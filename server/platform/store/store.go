@@ -0,0 +1,159 @@
+// Package store persists platform state: users, rooms, game results and their event logs
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Store errors
+var (
+	ErrUserNotFound   = errors.New("user not found")
+	ErrEmailTaken     = errors.New("email already registered")
+	ErrRoomNotFound   = errors.New("room not found")
+	ErrResultNotFound = errors.New("result not found")
+	ErrStatsNotFound  = errors.New("stats not found")
+	ErrEventsNotFound = errors.New("event log not found")
+	ErrStoreClosed    = errors.New("store closed")
+)
+
+// Store backends selectable in the configuration
+const (
+	BackendMemory = "memory"
+	BackendBolt   = "bolt"
+)
+
+// User represents a registered user.
+// The password hash is never sent to clients; the bolt store saves it separately.
+type User struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	Nickname     string    `json:"nickname"`
+	Avatar       string    `json:"avatar"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Room is the durable record of a room created through the API
+type Room struct {
+	ID        string          `json:"id"`
+	GameType  string          `json:"gameType"`
+	Settings  json.RawMessage `json:"settings,omitempty"`
+	Capacity  int             `json:"capacity"`
+	CreatedAt time.Time       `json:"createdAt"`
+	ClosedAt  *time.Time      `json:"closedAt,omitempty"`
+}
+
+// GameResult is the outcome of a finished game
+type GameResult struct {
+	ID        string          `json:"id"`
+	RoomID    string          `json:"roomId"`
+	GameType  string          `json:"gameType"`
+	Settings  json.RawMessage `json:"settings,omitempty"`
+	Seed      int64           `json:"seed"` // Seeds the game's random draws; starting with it replays the same game
+	StartedAt time.Time       `json:"startedAt"`
+	EndedAt   time.Time       `json:"endedAt"`
+	Players   []PlayerResult  `json:"players"`
+}
+
+// PlayerResult is a single player's final standing in a game
+type PlayerResult struct {
+	PlayerID  string `json:"playerId"`
+	UserID    string `json:"userId,omitempty"`
+	Nickname  string `json:"nickname"`
+	Avatar    string `json:"avatar"`
+	Team      string `json:"team,omitempty"`
+	Rank      int    `json:"rank"`
+	Score     int    `json:"score"`
+	Collected int    `json:"collected,omitempty"` // Envelopes grabbed, moles hit or pairs matched
+}
+
+// PlayerStats are a registered user's lifetime statistics for one game type
+type PlayerStats struct {
+	UserID         string    `json:"userId"`
+	GameType       string    `json:"gameType"`
+	GamesPlayed    int       `json:"gamesPlayed"`
+	Wins           int       `json:"wins"`
+	BestScore      int       `json:"bestScore"`
+	TotalScore     int       `json:"totalScore"`
+	RankSum        int       `json:"rankSum"`
+	AverageRank    float64   `json:"averageRank"`
+	TotalCollected int       `json:"totalCollected"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// UserStore persists registered users
+type UserStore interface {
+	// CreateUser stores a new user, failing with ErrEmailTaken if the email is in use
+	CreateUser(user *User) error
+	// GetUser returns the user with the given ID
+	GetUser(id string) (*User, error)
+	// GetUserByEmail returns the user registered with the given email
+	GetUserByEmail(email string) (*User, error)
+}
+
+// RoomStore persists rooms and their settings
+type RoomStore interface {
+	// SaveRoom creates or replaces a room record with its settings
+	SaveRoom(room *Room) error
+	// GetRoom returns the room with the given ID, including closed rooms
+	GetRoom(id string) (*Room, error)
+	// CloseRoom records when a room was closed
+	CloseRoom(id string, closedAt time.Time) error
+}
+
+// ResultStore persists finished games
+type ResultStore interface {
+	// SaveResult stores a finished game
+	SaveResult(result *GameResult) error
+	// GetResult returns the result with the given ID
+	GetResult(id string) (*GameResult, error)
+	// ListResults returns a room's results, oldest first
+	ListResults(roomID string) ([]*GameResult, error)
+}
+
+// StatsStore persists lifetime player statistics
+type StatsStore interface {
+	// GetStats returns a user's statistics for a game type
+	GetStats(userID, gameType string) (*PlayerStats, error)
+	// ListStats returns a user's statistics for every game type they played
+	ListStats(userID string) ([]*PlayerStats, error)
+	// SaveStats creates or replaces a user's statistics for a game type
+	SaveStats(stats *PlayerStats) error
+}
+
+// EventStore persists the event logs of finished games
+type EventStore interface {
+	// SaveEvents stores a game's event log, encoded as JSON Lines, under its result ID
+	SaveEvents(resultID string, events []byte) error
+	// GetEvents returns the JSON Lines event log of a recorded game
+	GetEvents(resultID string) ([]byte, error)
+}
+
+// Store persists all platform state
+type Store interface {
+	UserStore
+	RoomStore
+	ResultStore
+	StatsStore
+	EventStore
+	// Err reports why changes are not reaching durable storage, nil while they are
+	Err() error
+	// Close flushes and releases the store
+	Close() error
+}
+
+// Open creates the store for the configured backend.
+// The bolt backend keeps its data in a bbolt database file at path.
+func Open(backend, path string) (Store, error) {
+	switch backend {
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendBolt:
+		return OpenBoltStore(path)
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", backend)
+	}
+}
@@ -0,0 +1,52 @@
+// Package teams lets the host set up team play in a room
+package teams
+
+import (
+	"log"
+
+	"gaming-platform/core"
+	"gaming-platform/core/message"
+	"gaming-platform/platform/room"
+)
+
+// SetTeamsPayload is the team setup sent by the host in hostSetTeams.
+// An empty list of names turns team play off.
+type SetTeamsPayload struct {
+	Names       []string          `json:"names" validate:"omitempty,min=2,max=10,dive,required,max=32"`
+	Scoring     string            `json:"scoring" validate:"omitempty,oneof=sum average"`
+	AutoBalance bool              `json:"autoBalance"`
+	Assignments map[string]string `json:"assignments"` // Player ID to team name
+}
+
+// init registers the team setup message
+func init() {
+	message.RegisterHostHandler("hostSetTeams", handleSetTeams, SetTeamsPayload{})
+}
+
+// handleSetTeams replaces the room's teams and assigns the players to them
+func handleSetTeams(gameRoom *core.Room, client *core.Client, msg core.Message) error {
+	payload := msg.Data.(*SetTeamsPayload)
+
+	if gameRoom.Game != nil && gameRoom.GameStarted && !gameRoom.GameEnded {
+		return core.NewError(core.ErrCodeGameInProgress, "teams cannot change while %s is running", gameRoom.Game.Type())
+	}
+
+	var settings *core.TeamSettings
+	if len(payload.Names) > 0 {
+		settings = room.NormalizeTeams(&core.TeamSettings{
+			Names:   payload.Names,
+			Scoring: payload.Scoring,
+		})
+		if settings == nil {
+			return &core.Error{
+				Code:    core.ErrCodeInvalidPayload,
+				Message: "teams need at least two distinct names",
+				Fields:  []core.FieldError{{Field: "names", Rule: "unique"}},
+			}
+		}
+	}
+
+	log.Printf("[TEAMS] Host %s is setting teams in room %s", client.Nickname, gameRoom.ID)
+	room.SetTeams(gameRoom, settings, payload.AutoBalance, payload.Assignments)
+	return nil
+}
@@ -0,0 +1,15 @@
+package tournament
+
+import "encoding/json"
+
+// StartPayload is the playlist sent by the host in hostStartTournament
+type StartPayload struct {
+	Rounds       []RoundPayload `json:"rounds" validate:"required,min=1,max=20,dive"`
+	BreakSeconds int            `json:"breakSeconds" validate:"omitempty,min=1,max=600"`
+}
+
+// RoundPayload is one game of the playlist with its start settings
+type RoundPayload struct {
+	GameType string          `json:"gameType" validate:"required"`
+	Settings json.RawMessage `json:"settings"`
+}
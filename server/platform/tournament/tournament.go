@@ -0,0 +1,178 @@
+// Package tournament runs a playlist of games back to back in one room and
+// keeps cumulative standings across the rounds
+package tournament
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"gaming-platform/core"
+	"gaming-platform/core/message"
+	"gaming-platform/platform/results"
+	"gaming-platform/platform/room"
+	"gaming-platform/platform/store"
+)
+
+// roundPoints is what the best player of a round earns; the others earn
+// points in proportion to their score
+const roundPoints = 1000
+
+// defaultBreakSeconds is the pause between rounds when the host sets none
+const defaultBreakSeconds = 10
+
+// init registers the tournament message and listens for finished rounds
+func init() {
+	message.RegisterHostHandler("hostStartTournament", handleStartTournament, StartPayload{})
+	results.OnRecorded(handleRoundEnd)
+}
+
+// handleStartTournament validates the host's playlist and starts its first round
+func handleStartTournament(gameRoom *core.Room, client *core.Client, msg core.Message) error {
+	payload := msg.Data.(*StartPayload)
+
+	if gameRoom.Tournament.Running() {
+		return core.NewError(core.ErrCodeGameInProgress, "a tournament is already running in room %s", gameRoom.ID)
+	}
+	if gameRoom.Game != nil && gameRoom.GameStarted && !gameRoom.GameEnded {
+		return core.NewError(core.ErrCodeGameInProgress, "%s is already running in room %s", gameRoom.Game.Type(), gameRoom.ID)
+	}
+
+	// Check every round up front so the playlist cannot fail halfway through
+	rounds := make([]core.TournamentRound, 0, len(payload.Rounds))
+	for _, round := range payload.Rounds {
+		if err := message.ValidateSettings(round.GameType, round.Settings); err != nil {
+			return err
+		}
+		rounds = append(rounds, core.TournamentRound{
+			GameType: round.GameType,
+			Settings: round.Settings,
+		})
+	}
+
+	breakSeconds := payload.BreakSeconds
+	if breakSeconds == 0 {
+		breakSeconds = defaultBreakSeconds
+	}
+
+	tournament := &core.Tournament{
+		Rounds:       rounds,
+		BreakSeconds: breakSeconds,
+		Standings:    make(map[string]*core.Standing),
+	}
+	gameRoom.Tournament = tournament
+	log.Printf("[TOURNAMENT] Host %s started a %d round tournament in room %s", client.Nickname, len(rounds), gameRoom.ID)
+
+	if err := startRound(gameRoom, tournament); err != nil {
+		gameRoom.Tournament = nil
+		return err
+	}
+	return nil
+}
+
+// startRound announces and starts the tournament's current round
+func startRound(gameRoom *core.Room, tournament *core.Tournament) error {
+	round := tournament.Rounds[tournament.Current]
+
+	room.BroadcastToRoom(gameRoom, map[string]interface{}{
+		"type": "tournamentRoundStarted",
+		"data": map[string]interface{}{
+			"round":       tournament.Current + 1,
+			"totalRounds": len(tournament.Rounds),
+			"gameType":    round.GameType,
+		},
+	})
+	log.Printf("[TOURNAMENT] Starting round %d/%d (%s) in room %s",
+		tournament.Current+1, len(tournament.Rounds), round.GameType, gameRoom.ID)
+
+	return message.StartRound(gameRoom, round.GameType, round.Settings)
+}
+
+// handleRoundEnd adds a finished round to the standings and schedules the next one
+func handleRoundEnd(gameRoom *core.Room, result *store.GameResult) {
+	tournament := gameRoom.Tournament
+	if !tournament.Running() || result.GameType != tournament.Rounds[tournament.Current].GameType {
+		return
+	}
+
+	addRoundPoints(tournament, result.Players)
+	tournament.Rounds[tournament.Current].ResultID = result.ID
+	tournament.Current++
+	tournament.Finished = tournament.Current >= len(tournament.Rounds)
+
+	// Announce the standings after the game's own end message
+	gameRoom.After(0, func() {
+		broadcastStandings(gameRoom, tournament)
+	})
+
+	if tournament.Finished {
+		log.Printf("[TOURNAMENT] Tournament finished in room %s", gameRoom.ID)
+		return
+	}
+	tournament.StopBreak = gameRoom.After(time.Duration(tournament.BreakSeconds)*time.Second, func() {
+		tournament.StopBreak = nil
+		if err := startRound(gameRoom, tournament); err != nil {
+			log.Printf("[TOURNAMENT] Failed to start round %d in room %s: %v", tournament.Current+1, gameRoom.ID, err)
+			tournament.Cancel()
+			broadcastStandings(gameRoom, tournament)
+		}
+	})
+}
+
+// addRoundPoints normalizes a round's scores and adds them to the standings.
+// The best score earns roundPoints; a round where nobody scored earns nothing.
+func addRoundPoints(tournament *core.Tournament, players []store.PlayerResult) {
+	bestScore := 0
+	for _, player := range players {
+		if player.Score > bestScore {
+			bestScore = player.Score
+		}
+	}
+
+	for _, player := range players {
+		standing, exists := tournament.Standings[player.PlayerID]
+		if !exists {
+			// Players who joined late scored nothing in the earlier rounds
+			standing = &core.Standing{
+				PlayerID: player.PlayerID,
+				Rounds:   make([]int, tournament.Current),
+			}
+			tournament.Standings[player.PlayerID] = standing
+		}
+		standing.Nickname = player.Nickname
+
+		points := 0
+		if bestScore > 0 && player.Score > 0 {
+			points = int(math.Round(float64(player.Score) * roundPoints / float64(bestScore)))
+		}
+		standing.Points += points
+		standing.Rounds = append(standing.Rounds, points)
+	}
+
+	// Players who missed this round get zero for it
+	for _, standing := range tournament.Standings {
+		for len(standing.Rounds) <= tournament.Current {
+			standing.Rounds = append(standing.Rounds, 0)
+		}
+	}
+}
+
+// broadcastStandings sends the cumulative standings to everyone in the room
+func broadcastStandings(gameRoom *core.Room, tournament *core.Tournament) {
+	data := map[string]interface{}{
+		"roundsPlayed": tournament.Current,
+		"totalRounds":  len(tournament.Rounds),
+		"rounds":       tournament.Rounds,
+		"standings":    tournament.Table(),
+		"finished":     tournament.Finished,
+	}
+	if !tournament.Finished {
+		data["nextGameType"] = tournament.Rounds[tournament.Current].GameType
+		data["breakSeconds"] = tournament.BreakSeconds
+	}
+
+	room.BroadcastToRoom(gameRoom, map[string]interface{}{
+		"type": "tournamentStandings",
+		"data": data,
+	})
+}
@@ -0,0 +1,56 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+
+	"gaming-platform/core"
+	"gaming-platform/platform/store"
+)
+
+func TestAddRoundPoints(t *testing.T) {
+	tournament := &core.Tournament{
+		Rounds:    make([]core.TournamentRound, 3),
+		Standings: make(map[string]*core.Standing),
+	}
+
+	// Round one: scores are scaled so the best earns roundPoints
+	addRoundPoints(tournament, []store.PlayerResult{
+		{PlayerID: "a", Nickname: "Ann", Score: 80},
+		{PlayerID: "b", Nickname: "Bob", Score: 20},
+	})
+	tournament.Current++
+
+	// Round two: Ann missed it and Cid joined late
+	addRoundPoints(tournament, []store.PlayerResult{
+		{PlayerID: "b", Nickname: "Bob", Score: 30},
+		{PlayerID: "c", Nickname: "Cid", Score: 10},
+	})
+	tournament.Current++
+
+	// Round three: nobody scored
+	addRoundPoints(tournament, []store.PlayerResult{
+		{PlayerID: "a", Nickname: "Ann", Score: 0},
+		{PlayerID: "b", Nickname: "Bob", Score: 0},
+	})
+
+	want := map[string]core.Standing{
+		"a": {PlayerID: "a", Nickname: "Ann", Points: 1000, Rounds: []int{1000, 0, 0}},
+		"b": {PlayerID: "b", Nickname: "Bob", Points: 1250, Rounds: []int{250, 1000, 0}},
+		"c": {PlayerID: "c", Nickname: "Cid", Points: 333, Rounds: []int{0, 333, 0}},
+	}
+	if len(tournament.Standings) != len(want) {
+		t.Fatalf("%d standings, want %d", len(tournament.Standings), len(want))
+	}
+	for id, expected := range want {
+		got := tournament.Standings[id]
+		if got == nil || !reflect.DeepEqual(*got, expected) {
+			t.Errorf("standing %s = %+v, want %+v", id, got, expected)
+		}
+	}
+
+	table := tournament.Table()
+	if table[0].PlayerID != "b" || table[0].Rank != 1 || table[2].PlayerID != "c" {
+		t.Errorf("table = %+v", table)
+	}
+}
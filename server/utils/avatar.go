@@ -1,10 +1,7 @@
 // Package utils provides utility functions for the gaming platform
 package utils
 
-import (
-	"math/rand"
-	"time"
-)
+import "math/rand"
 
 // AnimalAvatars contains all available animal avatar names
 var AnimalAvatars = []string{
@@ -29,10 +26,15 @@ var AnimalAvatars = []string{
 
 // GetRandomAvatar returns a random animal avatar name
 func GetRandomAvatar() string {
-	rand.Seed(time.Now().UnixNano())
 	return AnimalAvatars[rand.Intn(len(AnimalAvatars))]
 }
 
+// PickAvatar returns an animal avatar name drawn from rng, so the same seed
+// picks the same avatars
+func PickAvatar(rng *rand.Rand) string {
+	return AnimalAvatars[rng.Intn(len(AnimalAvatars))]
+}
+
 // GetAvatarByIndex returns an avatar by index (useful for deterministic assignment)
 func GetAvatarByIndex(index int) string {
 	if index < 0 || index >= len(AnimalAvatars) {
@@ -44,4 +46,14 @@ func GetAvatarByIndex(index int) string {
 // GetAvatarCount returns the total number of available avatars
 func GetAvatarCount() int {
 	return len(AnimalAvatars)
-}
\ No newline at end of file
+}
+
+// IsValidAvatar reports whether name is one of the available avatars
+func IsValidAvatar(name string) bool {
+	for _, avatar := range AnimalAvatars {
+		if avatar == name {
+			return true
+		}
+	}
+	return false
+}